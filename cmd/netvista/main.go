@@ -2,10 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -13,15 +13,10 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/ismailtsdln/netvista/internal/core/domain"
-	"github.com/ismailtsdln/netvista/internal/core/ports"
-	"github.com/ismailtsdln/netvista/internal/core/services"
 	"github.com/ismailtsdln/netvista/internal/engine"
-	"github.com/ismailtsdln/netvista/internal/infra/adapters"
-	"github.com/ismailtsdln/netvista/internal/plugins"
+	"github.com/ismailtsdln/netvista/internal/progress"
+	"github.com/ismailtsdln/netvista/internal/server"
 	"github.com/ismailtsdln/netvista/pkg/config"
-	"github.com/ismailtsdln/netvista/pkg/models"
-	"github.com/ismailtsdln/netvista/pkg/signatures"
 	"github.com/ismailtsdln/netvista/pkg/utils"
 )
 
@@ -34,11 +29,16 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	slog.SetDefault(logger)
 
+	// The scan command prints the banner itself once it knows whether
+	// stdout carries results.
 	banner := utils.GetBanner(version)
-	color.Cyan(banner)
+	if len(os.Args) < 2 || os.Args[1] != "scan" {
+		color.Cyan(banner)
+	}
 
 	scanCmd := flag.NewFlagSet("scan", flag.ExitOnError)
 	scanCmd.Usage = func() {
+		color.Cyan(banner)
 		color.Cyan("\n [▶] NetVista Scan Engine - Usage")
 		fmt.Fprintf(os.Stderr, " Usage: netvista scan [options] < targets.txt\n\n")
 		color.Yellow(" Options:")
@@ -55,6 +55,8 @@ func main() {
 		fmt.Println("\n  # Scan via Proxy and auto-open report")
 		fmt.Println("  # Scan via Proxy and auto-open report")
 		fmt.Println("  echo \"target.local\" | ./netvista scan -proxy \"http://127.0.0.1:8080\" -open")
+		fmt.Println("\n  # Stream results as JSON lines into other tools")
+		fmt.Println("  cat targets.txt | ./netvista scan -jsonl -o reports | jq -r 'select(.IsAlive) | .URL'")
 	}
 
 	confPath := scanCmd.String("config", "netvista.yaml", "Path to config file")
@@ -69,7 +71,25 @@ func main() {
 	exportCSV := scanCmd.Bool("csv", true, "Export to CSV")
 	exportMD := scanCmd.Bool("md", true, "Export to Markdown")
 	exportTXT := scanCmd.Bool("txt", true, "Export to Text (alive URLs)")
+	singleFile := scanCmd.Bool("single-file", false, "Write a self-contained report.html with inlined assets and thumbnails")
 	autoOpen := scanCmd.Bool("open", false, "Automatically open the HTML report")
+	shotFormat := scanCmd.String("format", "", "Screenshot format: png, jpeg or webp")
+	shotQuality := scanCmd.Int("quality", 0, "Screenshot quality for jpeg/webp (1-100)")
+	thumbWidth := scanCmd.Int("thumb-width", -1, "Thumbnail width in pixels (0 disables thumbnails)")
+	secretRulesPath := scanCmd.String("secret-rules", "", "Secret detection rules file (defaults to built-in rules)")
+	followDiscovered := scanCmd.Int("follow", -1, "Max same-host paths found in scripts to add as new targets (0 disables)")
+	pathProbe := scanCmd.Bool("paths", false, "Probe common paths (robots.txt, sitemap.xml, .git/HEAD, ...) on each host")
+	wordlist := scanCmd.String("wordlist", "", "Extra paths to probe, one per line (implies -paths)")
+	dedupe := scanCmd.Bool("dedupe", false, "Name screenshots by content hash to deduplicate identical captures")
+	clusterThreshold := scanCmd.Int("cluster-threshold", -1, "Max pHash Hamming distance for visual clustering")
+	labelsPath := scanCmd.String("labels", "", "Cluster label library (YAML mapping known pHashes to names)")
+	consentPath := scanCmd.String("consent-rules", "", "Cookie consent/overlay dismissal rules file (YAML, defaults to built-in rules)")
+	showProgress := scanCmd.Bool("progress", true, "Show live progress (redrawn on a terminal, logged periodically otherwise)")
+	progressInterval := scanCmd.Duration("progress-interval", 10*time.Second, "How often to log progress when stderr is not a terminal")
+	notifyPath := scanCmd.String("notify", "", "Webhook notification config (YAML)")
+	jsonLines := scanCmd.Bool("jsonl", false, "Stream one JSON object per finished target to stdout (no banner or colors)")
+	jsonOut := scanCmd.Bool("json", false, "With -o -, same as -jsonl")
+	baselinePath := scanCmd.String("baseline", "", "Earlier results.json or report directory that notifications compare against (defaults to the output's results.json)")
 
 	serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
 	serveCmd.Usage = func() {
@@ -83,9 +103,24 @@ func main() {
 		fmt.Println("\n  # Serve custom directory on port 9090")
 		fmt.Println("  # Serve custom directory on port 9090")
 		fmt.Println("  ./netvista serve -d my_scan -p 9090")
+		fmt.Println("\n  # Browse several scans from one dashboard")
+		fmt.Println("  ./netvista serve -d prod_scan,staging_scan")
+		fmt.Println("\n  # Launch and monitor scans from the dashboard")
+		fmt.Println("  ./netvista serve -d reports -scans reports/live")
+		fmt.Println("\n  # Share over HTTPS with logins")
+		fmt.Println("  ./netvista serve -d reports -scans reports/live -bind 0.0.0.0 -tls -auth users.yaml")
 	}
-	serveDir := serveCmd.String("d", "reports", "Directory to serve")
+	serveDir := serveCmd.String("d", "reports", "Report directories to serve, comma-separated (a directory of reports serves each of them)")
 	servePort := serveCmd.String("p", "8080", "Port to serve on")
+	serveScans := serveCmd.String("scans", "", "Enable launching scans from the dashboard, writing their reports under this directory")
+	serveMaxScans := serveCmd.Int("max-scans", 1, "Scans allowed to run at once; others wait in a queue")
+	serveConfig := serveCmd.String("config", "netvista.yaml", "Config file with the defaults of launched scans")
+	serveBind := serveCmd.String("bind", "127.0.0.1", "Address to listen on (\"\" or 0.0.0.0 for all interfaces; needs -auth and -tls with -scans)")
+	serveTLS := serveCmd.Bool("tls", false, "Serve HTTPS with a self-signed certificate unless -tls-cert is given")
+	serveCert := serveCmd.String("tls-cert", "", "TLS certificate file (PEM); implies -tls")
+	serveKey := serveCmd.String("tls-key", "", "TLS private key file (PEM)")
+	serveAuth := serveCmd.String("auth", "", "YAML file of users and API tokens with viewer or admin roles")
+	serveAccessLog := serveCmd.Bool("access-log", true, "Log every HTTP request")
 
 	flag.Usage = func() {
 		color.Cyan(utils.GetBanner(version))
@@ -108,6 +143,20 @@ func main() {
 	case "scan":
 		scanCmd.Parse(os.Args[2:])
 
+		// Streaming keeps stdout for results: everything else goes to
+		// stderr, uncolored.
+		stream := *jsonLines || (*jsonOut && *output == "-")
+		if *jsonOut && !stream {
+			slog.Error("-json writes to stdout; use it with -o - or use -jsonl")
+			os.Exit(1)
+		}
+		if stream {
+			color.NoColor = true
+			color.Output = os.Stderr
+		} else {
+			color.Cyan(banner)
+		}
+
 		// Load YAML config
 		cfg, err := config.LoadConfig(*confPath)
 		if err != nil {
@@ -122,7 +171,7 @@ func main() {
 		if *concurrency == 0 {
 			*concurrency = cfg.Concurrency
 		}
-		if *output == "" {
+		if *output == "" || *output == "-" {
 			*output = cfg.Output
 		}
 		if *timeout == "" {
@@ -134,6 +183,45 @@ func main() {
 		if *headers == "" {
 			*headers = cfg.Headers
 		}
+		if *shotFormat == "" {
+			*shotFormat = cfg.ScreenshotFormat
+		}
+		if *shotQuality == 0 {
+			*shotQuality = cfg.ScreenshotQuality
+		}
+		if *thumbWidth < 0 {
+			*thumbWidth = cfg.ThumbnailWidth
+		}
+		if !*dedupe {
+			*dedupe = cfg.DedupeScreenshots
+		}
+		if *clusterThreshold < 0 {
+			*clusterThreshold = cfg.ClusterThreshold
+		}
+		if *labelsPath == "" {
+			*labelsPath = cfg.ClusterLabels
+		}
+		if *consentPath == "" {
+			*consentPath = cfg.ConsentRules
+		}
+		if *followDiscovered < 0 {
+			*followDiscovered = cfg.FollowDiscovered
+		}
+		if !*pathProbe {
+			*pathProbe = cfg.PathProbe
+		}
+		if *wordlist == "" {
+			*wordlist = cfg.PathWordlist
+		}
+		if !*singleFile {
+			*singleFile = cfg.SingleFileReport
+		}
+		if *secretRulesPath == "" {
+			*secretRulesPath = cfg.SecretRules
+		}
+		if *notifyPath == "" {
+			*notifyPath = cfg.Notify
+		}
 		if *redirects == 0 {
 			*redirects = 10 // Default
 		}
@@ -145,50 +233,60 @@ func main() {
 			os.Exit(1)
 		}
 
-		customHeaders := make(map[string]string)
-		if *headers != "" {
-			parts := strings.Split(*headers, ",")
-			for _, part := range parts {
-				kv := strings.SplitN(part, ":", 2)
-				if len(kv) == 2 {
-					customHeaders[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
-				}
+		opts := scanOptions{
+			Output:           *output,
+			Concurrency:      *concurrency,
+			Timeout:          d,
+			Proxy:            *proxy,
+			Headers:          parseHeaders(*headers),
+			ExportCSV:        *exportCSV,
+			ExportMD:         *exportMD,
+			ExportTXT:        *exportTXT,
+			SingleFile:       *singleFile,
+			ShotFormat:       *shotFormat,
+			ShotQuality:      *shotQuality,
+			ThumbWidth:       *thumbWidth,
+			Dedupe:           *dedupe,
+			ClusterThreshold: *clusterThreshold,
+			LabelsPath:       *labelsPath,
+			ConsentPath:      *consentPath,
+			SecretRulesPath:  *secretRulesPath,
+			FollowDiscovered: *followDiscovered,
+			PathProbe:        *pathProbe,
+			Wordlist:         *wordlist,
+		}
+		// On a terminal, logs go through the progress display so they scroll
+		// above its status block.
+		tracker := progress.NewTracker()
+		var display *progress.Display
+		if *showProgress {
+			stat, _ := os.Stderr.Stat()
+			tty := stat != nil && stat.Mode()&os.ModeCharDevice != 0
+			display = progress.NewDisplay(tracker, os.Stderr, tty, logger, *progressInterval)
+			if tty {
+				logger = slog.New(slog.NewTextHandler(display, &slog.HandlerOptions{Level: slog.LevelInfo}))
+				slog.SetDefault(logger)
 			}
 		}
 
-		// Load Signatures
-		sigPath := filepath.Join("pkg", "signatures", "signatures.yaml")
-		sigs, err := signatures.LoadSignatures(sigPath)
+		scannerService, closeScanner, err := buildScanner(cfg, opts, logger)
 		if err != nil {
-			slog.Warn("Failed to load signatures, some features may be limited", "path", sigPath, "error", err)
-			sigs = &signatures.Signatures{}
+			slog.Error("Failed to initialize scanner", "error", err)
+			os.Exit(1)
 		}
+		defer closeScanner()
 
-		// Initialize Adapters
-		proberAdapter := adapters.NewProberAdapter(d, *proxy, customHeaders)
-		rendererAdapter, err := adapters.NewRendererAdapter(*output, *proxy, false, cfg.MaxBrowserContexts)
-		if err != nil {
-			slog.Error("Failed to initialize renderer", "error", err)
+		// Subscribed before the scan so the baseline is read before
+		// results.json is rewritten.
+		if err := subscribeNotifier(scannerService, *notifyPath, *baselinePath, *output, logger); err != nil {
+			slog.Error("Failed to set up notifications", "error", err)
 			os.Exit(1)
 		}
-		defer rendererAdapter.Close()
-
-		reporterAdapter := adapters.NewReporterAdapter(*output, *exportCSV, *exportMD, *exportTXT)
 
-		wafAnalyzer := adapters.NewWafAnalyzerAdapter(plugins.NewWafPlugin(sigs.Wafs))
-
-		// Initialize Service
-		scannerService := services.NewScannerService(
-			proberAdapter,
-			rendererAdapter,
-			[]ports.Analyzer{wafAnalyzer},
-			reporterAdapter,
-			domain.Config{
-				Concurrency: *concurrency,
-				OutputPath:  *output,
-			},
-			logger,
-		)
+		if stream {
+			slog.Info("Streaming results to stdout", "reports", *output)
+			streamResults(scannerService, os.Stdout, *output, logger)
+		}
 
 		var rawTargets []string
 		var terr error
@@ -215,35 +313,22 @@ func main() {
 			os.Exit(0)
 		}
 
-		// Load existing results for incremental scanning
-		seenURLs := make(map[string]bool)
-		resultsPath := filepath.Join(*output, "results.json")
-		if data, terr := os.ReadFile(resultsPath); terr == nil {
-			var existing []models.Target
-			if err := json.Unmarshal(data, &existing); err == nil {
-				for _, r := range existing {
-					seenURLs[r.URL] = true
-				}
-				slog.Info("Loaded existing results for incremental scan", "count", len(existing))
-			}
-		}
-
-		resolvedTargets := utils.ResolveTargets(rawTargets)
-		var finalTargets []domain.Target
-		for _, rt := range resolvedTargets {
-			if seenURLs[rt] {
-				continue
-			}
-			finalTargets = append(finalTargets, domain.Target{URL: rt})
-		}
-
+		finalTargets := pendingTargets(rawTargets, *output, *notifyPath == "", logger)
 		if len(finalTargets) == 0 {
 			slog.Info("All targets already processed.")
 			os.Exit(0)
 		}
 
 		ctx := context.Background()
-		if err := scannerService.Scan(ctx, finalTargets); err != nil {
+		scannerService.OnEvent(tracker.Handle)
+		if display != nil {
+			display.Start()
+		}
+		err = scannerService.Scan(ctx, finalTargets)
+		if display != nil {
+			display.Stop()
+		}
+		if err != nil {
 			slog.Error("Scan failed", "error", err)
 			os.Exit(1)
 		}
@@ -253,21 +338,103 @@ func main() {
 			htmlPath := filepath.Join(*output, "report.html")
 			utils.OpenBrowser(htmlPath)
 		}
+		if stream {
+			return
+		}
 		color.Green("\n [✓] Scan complete! Results saved to: %s", *output)
 		color.Yellow(" [i] Run 'netvista serve -d %s' to view interactive dashboard.\n", *output)
 
 	case "serve":
 		serveCmd.Parse(os.Args[2:])
-		absPath, _ := filepath.Abs(*serveDir)
+		var dirs []string
+		for _, d := range strings.Split(*serveDir, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				dirs = append(dirs, d)
+			}
+		}
+		exposure, err := checkExposure(*serveBind, *serveScans != "", *serveAuth != "", *serveTLS || *serveCert != "")
+		if err != nil {
+			slog.Error("Refusing to serve", "error", err)
+			os.Exit(1)
+		}
+		srv, err := server.New(dirs, "web/templates/dashboard.html")
+		if err != nil {
+			slog.Error("Failed to open reports", "error", err)
+			os.Exit(1)
+		}
+		if *serveScans != "" {
+			cfg, err := config.LoadConfig(*serveConfig)
+			if err != nil {
+				slog.Error("Failed to load config", "error", err)
+				os.Exit(1)
+			}
+			run, err := scanRunner(cfg, logger)
+			if err != nil {
+				slog.Error("Invalid scan defaults", "error", err)
+				os.Exit(1)
+			}
+			if err := srv.EnableScans(*serveScans, run, *serveMaxScans); err != nil {
+				slog.Error("Failed to enable scans", "error", err)
+				os.Exit(1)
+			}
+		}
+
+		if *serveAuth != "" {
+			auth, err := server.LoadAuth(*serveAuth)
+			if err != nil {
+				slog.Error("Failed to load auth", "error", err)
+				os.Exit(1)
+			}
+			srv.SetAuth(auth)
+		}
+		if *serveAccessLog {
+			srv.SetLogger(logger)
+		}
+
+		tlsConfig, fingerprint, err := serveTLSConfig(*serveBind, *serveCert, *serveKey, *serveTLS)
+		if err != nil {
+			slog.Error("Failed to set up TLS", "error", err)
+			os.Exit(1)
+		}
+		httpServer := &http.Server{
+			Addr:              net.JoinHostPort(*serveBind, *servePort),
+			Handler:           srv.Handler(),
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		scheme, host := "http", *serveBind
+		if tlsConfig != nil {
+			scheme = "https"
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		baseURL := scheme + "://" + net.JoinHostPort(host, *servePort)
+
 		color.Cyan("\n [▶] NetVista Serve Engine")
 		color.White(" ──────────────────────────────────────────────────")
-		color.Green(" [✓] Serving reports from: %s", absPath)
-		color.Green(" [●] Dashboard available at: http://localhost:%s", *servePort)
+		for _, d := range dirs {
+			absPath, _ := filepath.Abs(d)
+			color.Green(" [✓] Serving reports from: %s", absPath)
+		}
+		color.Green(" [●] Dashboard available at: %s/", baseURL)
+		color.Green(" [●] JSON API available at: %s/api/reports", baseURL)
+		if fingerprint != "" {
+			color.Yellow(" [i] Self-signed certificate SHA-256: %s", fingerprint)
+		}
+		if exposure != "" {
+			color.Yellow(" [!] %s", exposure)
+		}
 		color.White(" ──────────────────────────────────────────────────")
 		color.Yellow(" [!] Press Ctrl+C to stop the server\n")
 
-		handler := http.FileServer(http.Dir(*serveDir))
-		if err := http.ListenAndServe(":"+*servePort, handler); err != nil {
+		if tlsConfig != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil {
 			slog.Error("Failed to start server", "error", err)
 			os.Exit(1)
 		}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/analyzers"
+	"github.com/ismailtsdln/netvista/internal/cluster"
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/core/ports"
+	"github.com/ismailtsdln/netvista/internal/core/services"
+	"github.com/ismailtsdln/netvista/internal/infra/adapters"
+	"github.com/ismailtsdln/netvista/internal/notify"
+	"github.com/ismailtsdln/netvista/internal/plugins"
+	"github.com/ismailtsdln/netvista/internal/screenshot"
+	"github.com/ismailtsdln/netvista/internal/server"
+	"github.com/ismailtsdln/netvista/pkg/config"
+	"github.com/ismailtsdln/netvista/pkg/consent"
+	"github.com/ismailtsdln/netvista/pkg/models"
+	"github.com/ismailtsdln/netvista/pkg/secrets"
+	"github.com/ismailtsdln/netvista/pkg/signatures"
+	"github.com/ismailtsdln/netvista/pkg/utils"
+)
+
+// scanOptions are the settings of one scan once flags and the YAML config
+// have been merged. The scan command and scans launched from serve share it.
+type scanOptions struct {
+	Output           string
+	Concurrency      int
+	Timeout          time.Duration
+	Proxy            string
+	Headers          map[string]string
+	ExportCSV        bool
+	ExportMD         bool
+	ExportTXT        bool
+	SingleFile       bool
+	ShotFormat       string
+	ShotQuality      int
+	ThumbWidth       int
+	Dedupe           bool
+	ClusterThreshold int
+	LabelsPath       string
+	ConsentPath      string
+	SecretRulesPath  string
+	FollowDiscovered int
+	PathProbe        bool
+	Wordlist         string
+}
+
+// defaultScanOptions returns the options of a scan configured by cfg alone.
+func defaultScanOptions(cfg *config.Config) (scanOptions, error) {
+	d, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return scanOptions{}, fmt.Errorf("invalid timeout %q: %w", cfg.Timeout, err)
+	}
+	return scanOptions{
+		Output:           cfg.Output,
+		Concurrency:      cfg.Concurrency,
+		Timeout:          d,
+		Proxy:            cfg.Proxy,
+		Headers:          parseHeaders(cfg.Headers),
+		ExportCSV:        true,
+		ExportMD:         true,
+		ExportTXT:        true,
+		SingleFile:       cfg.SingleFileReport,
+		ShotFormat:       cfg.ScreenshotFormat,
+		ShotQuality:      cfg.ScreenshotQuality,
+		ThumbWidth:       cfg.ThumbnailWidth,
+		Dedupe:           cfg.DedupeScreenshots,
+		ClusterThreshold: cfg.ClusterThreshold,
+		LabelsPath:       cfg.ClusterLabels,
+		ConsentPath:      cfg.ConsentRules,
+		SecretRulesPath:  cfg.SecretRules,
+		FollowDiscovered: cfg.FollowDiscovered,
+		PathProbe:        cfg.PathProbe,
+		Wordlist:         cfg.PathWordlist,
+	}, nil
+}
+
+// parseHeaders reads "Name: value, Other: value" into a header map.
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	if s == "" {
+		return headers
+	}
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) == 2 {
+			headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return headers
+}
+
+// buildScanner wires the adapters and analyzers for a scan. The returned
+// close function releases the browser and must be called when the scan is
+// done.
+func buildScanner(cfg *config.Config, opts scanOptions, logger *slog.Logger) (*services.ScannerService, func() error, error) {
+	// Load Signatures
+	sigPath := filepath.Join("pkg", "signatures", "signatures.yaml")
+	sigs, err := signatures.LoadSignatures(sigPath)
+	if err != nil {
+		logger.Warn("Failed to load signatures, some features may be limited", "path", sigPath, "error", err)
+		sigs = &signatures.Signatures{}
+	}
+
+	consentRules, err := consent.LoadRules(opts.ConsentPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load consent rules %s: %w", opts.ConsentPath, err)
+	}
+
+	clusterWeights, err := cluster.WeightsFromMap(cfg.ClusterWeights)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid cluster weights: %w", err)
+	}
+
+	secretRules, err := secrets.LoadRules(opts.SecretRulesPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load secret rules %s: %w", opts.SecretRulesPath, err)
+	}
+
+	var labelLibrary *cluster.LabelLibrary
+	if opts.LabelsPath != "" {
+		labelLibrary, err = cluster.LoadLabelLibrary(opts.LabelsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load cluster labels %s: %w", opts.LabelsPath, err)
+		}
+	}
+
+	var probePaths []analyzers.ProbePath
+	if opts.PathProbe || opts.Wordlist != "" {
+		probePaths = append(probePaths, analyzers.DefaultProbePaths...)
+		if opts.Wordlist != "" {
+			extra, err := analyzers.LoadWordlist(opts.Wordlist)
+			if err != nil {
+				return nil, nil, fmt.Errorf("load wordlist %s: %w", opts.Wordlist, err)
+			}
+			probePaths = append(probePaths, extra...)
+		}
+	}
+
+	// Initialize Adapters
+	proberAdapter := adapters.NewProberAdapter(opts.Timeout, opts.Proxy, opts.Headers, logger)
+	rendererAdapter, err := adapters.NewRendererAdapter(opts.Output, opts.Proxy, false, cfg.MaxBrowserContexts, consentRules, screenshot.ImageOptions{
+		Format:           opts.ShotFormat,
+		Quality:          opts.ShotQuality,
+		ThumbnailWidth:   opts.ThumbWidth,
+		ContentAddressed: opts.Dedupe,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("initialize renderer: %w", err)
+	}
+
+	reporterAdapter := adapters.NewReporterAdapter(opts.Output, opts.ExportCSV, opts.ExportMD, opts.ExportTXT, opts.SingleFile)
+
+	wafAnalyzer := adapters.NewWafAnalyzerAdapter(plugins.NewWafPlugin(sigs.Wafs))
+	analyzerClient := analyzers.NewHTTPClient(opts.Timeout, opts.Proxy, opts.Headers)
+
+	scanAnalyzers := []ports.Analyzer{
+		wafAnalyzer,
+		analyzers.NewClassifierAnalyzer(),
+		analyzers.NewHeadersAnalyzer(),
+		analyzers.NewScriptCollector(analyzerClient, cfg.MaxScripts, int64(cfg.MaxScriptKB)*1024,
+			analyzers.ScriptScope{SameSite: cfg.ScriptsSameSite, Domains: cfg.ScriptDomains}),
+		analyzers.NewSecretsAnalyzer(secretRules),
+		analyzers.NewEndpointAnalyzer(),
+		analyzers.NewTakeoverAnalyzer(analyzers.NewDNSResolver(cfg.DNSResolver, opts.Timeout), sigs.Takeovers),
+	}
+	if len(probePaths) > 0 {
+		scanAnalyzers = append(scanAnalyzers, analyzers.NewPathProber(analyzerClient, probePaths, rendererAdapter))
+	}
+
+	// Initialize Service
+	scannerService := services.NewScannerService(
+		proberAdapter,
+		rendererAdapter,
+		scanAnalyzers,
+		adapters.NewClusterAdapter(opts.ClusterThreshold, clusterWeights, labelLibrary),
+		reporterAdapter,
+		domain.Config{
+			Concurrency:   opts.Concurrency,
+			OutputPath:    opts.Output,
+			MaxDiscovered: opts.FollowDiscovered,
+		},
+		logger,
+	)
+	return scannerService, rendererAdapter.Close, nil
+}
+
+// subscribeNotifier loads the notification file at path and registers a
+// notifier for a scan writing to output. A non-empty baseline overrides the
+// one named in the file.
+func subscribeNotifier(scannerService *services.ScannerService, path, baseline, output string, logger *slog.Logger) error {
+	if path == "" {
+		return nil
+	}
+	notifyCfg, err := notify.LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("load notifications: %w", err)
+	}
+	if baseline != "" {
+		notifyCfg.Baseline = baseline
+	}
+	notifier, err := notify.New(notifyCfg, output, logger)
+	if err != nil {
+		return err
+	}
+	scannerService.Subscribe(notifier)
+	return nil
+}
+
+// streamResults writes every finished result of the scan to w as a line of
+// JSON. Nothing else may write to w while the scan runs.
+func streamResults(scannerService *services.ScannerService, w io.Writer, output string, logger *slog.Logger) {
+	scannerService.Subscribe(adapters.NewJSONLAdapter(w, output, logger))
+}
+
+// pendingTargets resolves raw inputs into targets. With incremental it skips
+// URLs already in output's results.json so that re-running a scan only adds
+// new hosts. Scans with notifications turn it off: they rescan known hosts to
+// compare them with the baseline.
+func pendingTargets(rawTargets []string, output string, incremental bool, logger *slog.Logger) []domain.Target {
+	seenURLs := make(map[string]bool)
+	resultsPath := filepath.Join(output, "results.json")
+	if data, err := os.ReadFile(resultsPath); incremental && err == nil {
+		var existing []models.Target
+		if err := json.Unmarshal(data, &existing); err == nil {
+			for _, r := range existing {
+				seenURLs[r.URL] = true
+			}
+			logger.Info("Loaded existing results for incremental scan", "count", len(existing))
+		}
+	}
+
+	var targets []domain.Target
+	for _, rt := range utils.ResolveTargets(rawTargets) {
+		if seenURLs[rt] {
+			continue
+		}
+		targets = append(targets, domain.Target{URL: rt})
+	}
+	return targets
+}
+
+// requestBaseline returns the report a submitted scan compares with for
+// notifications. Every scan writes a new directory, so without one only the
+// conditions that need no baseline can fire.
+func requestBaseline(req server.ScanRequest, dir string) string {
+	if req.Baseline == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(dir), req.Baseline)
+}
+
+// scanRunner runs scans submitted to serve with the options of cfg, applying
+// the overrides of each request.
+func scanRunner(cfg *config.Config, logger *slog.Logger) (server.ScanFunc, error) {
+	base, err := defaultScanOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, req server.ScanRequest, dir string, emit func(domain.Event)) error {
+		opts := base
+		opts.Output = dir
+		if req.Concurrency > 0 {
+			opts.Concurrency = req.Concurrency
+		}
+		if d, err := time.ParseDuration(req.Timeout); err == nil {
+			opts.Timeout = d
+		}
+		opts.PathProbe = opts.PathProbe || req.PathProbe
+
+		scanLogger := logger.With("scan", filepath.Base(dir))
+		scannerService, closeScanner, err := buildScanner(cfg, opts, scanLogger)
+		if err != nil {
+			return err
+		}
+		defer closeScanner()
+		scannerService.OnEvent(emit)
+		if err := subscribeNotifier(scannerService, cfg.Notify, requestBaseline(req, dir), dir, scanLogger); err != nil {
+			return err
+		}
+
+		targets := pendingTargets(req.Targets, dir, cfg.Notify == "", scanLogger)
+		if len(targets) == 0 {
+			return nil
+		}
+		return scannerService.Scan(ctx, targets)
+	}, nil
+}
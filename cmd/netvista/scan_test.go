@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/core/services"
+	"github.com/ismailtsdln/netvista/internal/infra/adapters"
+	"github.com/ismailtsdln/netvista/internal/server"
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+// TestStreamResults_StdoutIsJSON runs a scan in stream mode, including a
+// response body that fails to read, and checks that stdout only carries
+// JSON lines.
+func TestStreamResults_StdoutIsJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/truncated" {
+			// Promise more than is sent so reading the body fails.
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("<title>cut"))
+			return
+		}
+		w.Write([]byte("<html><title>ok</title></html>"))
+	}))
+	defer srv.Close()
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	output := t.TempDir()
+	svc := services.NewScannerService(
+		adapters.NewProberAdapter(2*time.Second, "", nil, logger),
+		nil, nil, nil, nil,
+		domain.Config{Concurrency: 2, OutputPath: output},
+		logger,
+	)
+	streamResults(svc, os.Stdout, output, logger)
+	scanErr := svc.Scan(context.Background(), []domain.Target{{URL: srv.URL + "/"}, {URL: srv.URL + "/truncated"}})
+	w.Close()
+	os.Stdout = stdout
+	require.NoError(t, scanErr)
+
+	var urls []string
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		var res models.Target
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &res), "stdout line %q", lines.Text())
+		urls = append(urls, res.URL)
+	}
+	assert.ElementsMatch(t, []string{srv.URL + "/", srv.URL + "/truncated"}, urls)
+}
+
+// TestPendingTargets_Notify checks that scans with notifications rescan
+// hosts already in the output so they can be compared with the baseline.
+func TestPendingTargets_Notify(t *testing.T) {
+	output := t.TempDir()
+	data, err := json.Marshal([]models.Target{{URL: "http://known.test"}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(output, "results.json"), data, 0o644))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	raw := []string{"http://known.test", "http://new.test"}
+
+	assert.Equal(t, []domain.Target{{URL: "http://new.test"}}, pendingTargets(raw, output, true, logger))
+	assert.Equal(t, []domain.Target{{URL: "http://known.test"}, {URL: "http://new.test"}}, pendingTargets(raw, output, false, logger))
+}
+
+// TestRequestBaseline checks that scans submitted to serve find the report
+// they name as a baseline beside their own directory.
+func TestRequestBaseline(t *testing.T) {
+	dir := filepath.Join("scans", "scan-20260101-000000")
+	assert.Equal(t, "", requestBaseline(server.ScanRequest{}, dir))
+	assert.Equal(t, filepath.Join("scans", "nightly"), requestBaseline(server.ScanRequest{Baseline: "nightly"}, dir))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/ismailtsdln/netvista/internal/server"
+)
+
+// serveTLSConfig returns the TLS configuration for serve, or nil for plain
+// HTTP. Without a certificate file it generates a self-signed certificate
+// for bind and the local host names and returns its fingerprint.
+func serveTLSConfig(bind, certFile, keyFile string, selfSigned bool) (*tls.Config, string, error) {
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, "", errors.New("-tls-cert and -tls-key must be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, "", nil
+	}
+	if !selfSigned {
+		return nil, "", nil
+	}
+
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil {
+		hosts = append(hosts, name)
+	}
+	if bind != "" && !isUnspecified(bind) {
+		hosts = append(hosts, bind)
+	}
+	cert, fingerprint, err := server.SelfSignedCert(hosts)
+	if err != nil {
+		return nil, "", err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, fingerprint, nil
+}
+
+// checkExposure refuses serve setups that hand control of the host to the
+// network: launching scans without logins, or logins sent in clear text.
+// It returns a warning for a read-only dashboard open to the network.
+func checkExposure(bind string, scans, auth, tls bool) (string, error) {
+	if isLoopback(bind) {
+		return "", nil
+	}
+	where := bind
+	if where == "" {
+		where = "all interfaces"
+	}
+	switch {
+	case scans && !auth:
+		return "", fmt.Errorf("-scans on %s would let anyone on the network launch scans; add -auth or bind to 127.0.0.1", where)
+	case auth && !tls:
+		return "", fmt.Errorf("-auth on %s would send passwords and tokens in clear text; add -tls or bind to 127.0.0.1", where)
+	case !auth:
+		return "No -auth: anyone who can reach this port can read and triage the reports", nil
+	}
+	return "", nil
+}
+
+func isUnspecified(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// isLoopback reports whether a bind address only accepts local connections.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExposure(t *testing.T) {
+	tests := []struct {
+		name              string
+		bind              string
+		scans, auth, tls  bool
+		wantErr, wantWarn bool
+	}{
+		{name: "loopback with scans", bind: "127.0.0.1", scans: true},
+		{name: "localhost", bind: "localhost", scans: true, auth: true},
+		{name: "open dashboard", bind: "0.0.0.0", wantWarn: true},
+		{name: "open scans", bind: "0.0.0.0", scans: true, wantErr: true},
+		{name: "all interfaces scans", bind: "", scans: true, auth: true, wantErr: true},
+		{name: "auth without tls", bind: "10.0.0.5", auth: true, wantErr: true},
+		{name: "auth with tls", bind: "10.0.0.5", scans: true, auth: true, tls: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := checkExposure(tt.bind, tt.scans, tt.auth, tt.tls)
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+			assert.Equal(t, tt.wantWarn, warning != "", "warning: %q", warning)
+		})
+	}
+}
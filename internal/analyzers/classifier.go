@@ -0,0 +1,226 @@
+// Package analyzers contains ports.Analyzer implementations that work
+// directly on domain scan results.
+package analyzers
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// Page classes assigned by ClassifierAnalyzer.
+const (
+	ClassLogin            = "login"
+	ClassAdminPanel       = "admin-panel"
+	ClassDefaultPage      = "default-page"
+	ClassDirectoryListing = "directory-listing"
+	ClassError            = "error"
+	ClassParked           = "parked"
+	ClassAPI              = "api"
+)
+
+var (
+	defaultPageTitles = []string{
+		"welcome to nginx", "apache2 ubuntu default page", "apache2 debian default page",
+		"test page for the apache http server", "test page for the nginx http server",
+		"iis windows server", "internet information services", "iis7", "iis8",
+		"welcome to centos", "it works!", "default web site page", "welcome to openresty",
+		"apache tomcat/", "welcome to jboss", "welcome to wildfly", "caddy works",
+		"plesk default page", "web server's default page", "domain default page",
+		"welcome to your new site", "congratulations", "hello world",
+	}
+	// Phrases that only a parked or for-sale page says about itself.
+	parkedPhrases = []string{
+		"this domain is for sale", "this domain name is for sale", "domain may be for sale",
+		"buy this domain", "this domain is parked", "parked free", "is available for purchase",
+	}
+	// Weaker phrases, which count only on a page linking to a parking or
+	// domain sale service.
+	parkedHints     = wordPattern("for sale", "parked", "make an offer", "buy now")
+	parkingHosts    = []string{"sedoparking.com", "parkingcrew.net", "bodis.com", "dan.com", "afternic.com"}
+	linkHostPattern = regexp.MustCompile(`(?i)(?:href|src)\s*=\s*["']?(?:https?:)?//([^/"'\s>?#:]+)`)
+	errorTitles     = []string{
+		"404 not found", "not found", "403 forbidden", "forbidden", "500 internal server error",
+		"internal server error", "502 bad gateway", "bad gateway", "503 service unavailable",
+		"service unavailable", "504 gateway time-out", "error", "page not found",
+		"access denied", "request rejected",
+	}
+	// Matched on word boundaries so that e.g. "ilo" does not match "pilot".
+	adminMarkers = wordPattern("admin", "administrator", "administration", "dashboard",
+		"control panel", "console", "management", "phpmyadmin", "cpanel", "webmin", "plesk",
+		"grafana", "kibana", "jenkins", "portainer", "router", "firewall", "fortigate",
+		"pfsense", "sonarqube", "gitlab", "argo cd", "vcenter", "idrac", "ilo", "zabbix", "nagios")
+	loginMarkers = wordPattern("login", "log in", "sign in", "signin", "logon", "authenticate")
+	adminPaths   = []string{"/admin", "/manager", "/console", "/dashboard", "/wp-admin"}
+)
+
+func wordPattern(words ...string) *regexp.Regexp {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// ClassifierAnalyzer assigns a page class (login, admin panel, default page,
+// directory listing, error, parked domain or API endpoint) to each result.
+type ClassifierAnalyzer struct{}
+
+// NewClassifierAnalyzer creates a new page classifier.
+func NewClassifierAnalyzer() *ClassifierAnalyzer {
+	return &ClassifierAnalyzer{}
+}
+
+// Name returns the analyzer name.
+func (a *ClassifierAnalyzer) Name() string {
+	return "Page Classification"
+}
+
+// Analyze sets result.PageClass. Pages that match no class are left unset.
+func (a *ClassifierAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive {
+		return nil
+	}
+	result.PageClass = Classify(result)
+	return nil
+}
+
+// Classify returns the page class for a result, checked from the most to the
+// least specific signal.
+func Classify(result *domain.ScanResult) string {
+	md := &result.Metadata
+	title := strings.ToLower(strings.TrimSpace(md.Title))
+	if title == "no title" {
+		title = ""
+	}
+	body := strings.ToLower(md.Body)
+	path := ""
+	if u, err := url.Parse(result.Target.URL); err == nil {
+		path = strings.ToLower(u.Path)
+	}
+
+	switch {
+	case isAPI(md):
+		return ClassAPI
+	case strings.HasPrefix(title, "index of /") || strings.HasPrefix(title, "directory listing for") ||
+		strings.Contains(body, "[to parent directory]") || strings.Contains(body, "<h1>index of /"):
+		return ClassDirectoryListing
+	case isParked(title, body):
+		return ClassParked
+	case md.StatusCode < 400 && hasPrefixAny(title, defaultPageTitles):
+		return ClassDefaultPage
+	case md.StatusCode == 401 && header(md, "WWW-Authenticate") != "":
+		if adminMarkers.MatchString(title) || adminMarkers.MatchString(header(md, "WWW-Authenticate")) {
+			return ClassAdminPanel
+		}
+		return ClassLogin
+	case md.StatusCode >= 400 || equalsAny(title, errorTitles):
+		return ClassError
+	}
+
+	hasPassword := false
+	for _, f := range md.Forms {
+		if f.HasPassword {
+			hasPassword = true
+			break
+		}
+	}
+	loginTitle := loginMarkers.MatchString(title)
+	loginHint := hasPassword || (len(md.Forms) > 0 && loginTitle)
+
+	// An admin title alone fits plenty of ordinary pages ("Sales Dashboard"),
+	// so it needs a password form as well; a page that calls itself a login
+	// page is a login page whatever it guards.
+	switch {
+	case hasPrefixAny(path, adminPaths):
+		return ClassAdminPanel
+	case loginHint && loginTitle:
+		return ClassLogin
+	case hasPassword && adminMarkers.MatchString(title):
+		return ClassAdminPanel
+	case loginHint:
+		return ClassLogin
+	}
+	return ""
+}
+
+func isParked(title, body string) bool {
+	if containsAny(title, parkedPhrases) || containsAny(body, parkedPhrases) {
+		return true
+	}
+	if !parkedHints.MatchString(title) && !parkedHints.MatchString(body) {
+		return false
+	}
+	for _, m := range linkHostPattern.FindAllStringSubmatch(body, -1) {
+		host := strings.TrimSuffix(m[1], ".")
+		for _, p := range parkingHosts {
+			if host == p || strings.HasSuffix(host, "."+p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isAPI(md *domain.Metadata) bool {
+	ct := strings.ToLower(header(md, "Content-Type"))
+	if strings.Contains(ct, "json") || strings.Contains(ct, "application/xml") ||
+		strings.Contains(ct, "application/problem") || strings.Contains(ct, "application/grpc") {
+		return true
+	}
+	trimmed := strings.TrimSpace(md.Body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(trimmed))
+}
+
+// header looks up a response header case-insensitively.
+func header(md *domain.Metadata, key string) string {
+	if v, ok := md.Headers[key]; ok {
+		return v
+	}
+	for k, v := range md.Headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func containsAny(s string, needles []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, n := range needles {
+		if strings.Contains(s, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefixAny(s string, prefixes []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalsAny(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		md     domain.Metadata
+		expect string
+	}{
+		{
+			name:   "password form",
+			url:    "https://app.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Welcome", Forms: []domain.Form{{HasPassword: true}}},
+			expect: ClassLogin,
+		},
+		{
+			name:   "basic auth",
+			url:    "https://router.example.com/",
+			md:     domain.Metadata{StatusCode: 401, Headers: map[string]string{"Www-Authenticate": `Basic realm="x"`}},
+			expect: ClassLogin,
+		},
+		{
+			name:   "admin title",
+			url:    "https://ci.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Dashboard [Jenkins]", Forms: []domain.Form{{HasPassword: true}}},
+			expect: ClassAdminPanel,
+		},
+		{
+			name:   "admin path",
+			url:    "https://example.com/wp-admin/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Site"},
+			expect: ClassAdminPanel,
+		},
+		{
+			name:   "admin title without a login form",
+			url:    "https://sales.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Sales Dashboard", Forms: []domain.Form{{Inputs: []string{"q"}}}},
+			expect: "",
+		},
+		{
+			name:   "admin marker in a blog title",
+			url:    "https://about.gitlab.com/blog/",
+			md:     domain.Metadata{StatusCode: 200, Title: "GitLab Blog"},
+			expect: "",
+		},
+		{
+			name:   "login title wins over admin marker",
+			url:    "http://192.168.1.1/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Router Login", Forms: []domain.Form{{HasPassword: true}}},
+			expect: ClassLogin,
+		},
+		{
+			name:   "basic auth realm names a console",
+			url:    "https://fw.example.com/",
+			md:     domain.Metadata{StatusCode: 401, Headers: map[string]string{"WWW-Authenticate": `Basic realm="pfSense"`}},
+			expect: ClassAdminPanel,
+		},
+		{
+			name:   "admin marker needs a whole word",
+			url:    "https://example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Autopilot consoles"},
+			expect: "",
+		},
+		{
+			name:   "default nginx",
+			url:    "http://10.0.0.1/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Welcome to nginx!"},
+			expect: ClassDefaultPage,
+		},
+		{
+			name:   "directory listing",
+			url:    "http://files.example.com/pub/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Index of /pub"},
+			expect: ClassDirectoryListing,
+		},
+		{
+			name:   "error status",
+			url:    "https://example.com/",
+			md:     domain.Metadata{StatusCode: 503, Title: "Maintenance"},
+			expect: ClassError,
+		},
+		{
+			name:   "error title on 200",
+			url:    "https://example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "404 Not Found"},
+			expect: ClassError,
+		},
+		{
+			name:   "parked",
+			url:    "https://example.net/",
+			md:     domain.Metadata{StatusCode: 200, Title: "example.net", Body: "<p>This domain is for sale!</p>"},
+			expect: ClassParked,
+		},
+		{
+			name:   "sale hint linking to a domain marketplace",
+			url:    "https://example.net/",
+			md:     domain.Metadata{StatusCode: 200, Title: "example.net", Body: `<h1>Make an offer</h1><a href="https://dan.com/buy-domain/example.net">Buy</a>`},
+			expect: ClassParked,
+		},
+		{
+			name:   "registrar name in another domain",
+			url:    "https://jordan.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Jordan", Body: `<a href="https://jordan.com/shop">Shop</a> Tickets for sale at sudan.com`},
+			expect: "",
+		},
+		{
+			name:   "link to a registrar without a sale phrase",
+			url:    "https://blog.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Blog", Body: `I moved my domain to <a href="https://www.afternic.com/">Afternic</a>; domain parking is a big business.`},
+			expect: "",
+		},
+		{
+			name:   "json content type",
+			url:    "https://api.example.com/",
+			md:     domain.Metadata{StatusCode: 404, Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"error":"not found"}`},
+			expect: ClassAPI,
+		},
+		{
+			name:   "json body without content type",
+			url:    "https://api.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Body: ` [1, 2, 3]`},
+			expect: ClassAPI,
+		},
+		{
+			name:   "plain page",
+			url:    "https://www.example.com/",
+			md:     domain.Metadata{StatusCode: 200, Title: "Example Corp", Forms: []domain.Form{{Inputs: []string{"q"}}}},
+			expect: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &domain.ScanResult{Target: domain.Target{URL: tt.url}, Metadata: tt.md, IsAlive: true}
+			if got := Classify(res); got != tt.expect {
+				t.Errorf("Classify() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
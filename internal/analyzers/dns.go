@@ -0,0 +1,186 @@
+package analyzers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxCNAMEHops bounds how far a CNAME chain is followed.
+const maxCNAMEHops = 10
+
+// DNSAnswer is the outcome of resolving a hostname.
+type DNSAnswer struct {
+	Chain     []string // CNAME targets in order, without trailing dots
+	Addresses []string // A/AAAA records of the last name in the chain
+	NXDomain  bool     // The resolver answered NXDOMAIN
+	RCode     string   // Response code, e.g. "RCodeSuccess"
+}
+
+// Dangling reports whether the hostname is a CNAME to a name that does not
+// resolve.
+func (a *DNSAnswer) Dangling() bool {
+	return len(a.Chain) > 0 && (a.NXDomain || len(a.Addresses) == 0)
+}
+
+// Resolver resolves the CNAME chain of a hostname.
+type Resolver interface {
+	Resolve(ctx context.Context, host string) (*DNSAnswer, error)
+}
+
+// DNSResolver sends plain UDP queries to a single recursive server so that
+// the CNAME chain and response code are visible, which net.Resolver hides.
+type DNSResolver struct {
+	server  string
+	timeout time.Duration
+}
+
+// NewDNSResolver creates a resolver using server ("host:port"). An empty
+// server uses the first nameserver in /etc/resolv.conf, or 8.8.8.8.
+func NewDNSResolver(server string, timeout time.Duration) *DNSResolver {
+	if server == "" {
+		server = systemNameserver()
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &DNSResolver{server: server, timeout: timeout}
+}
+
+func systemNameserver() string {
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return "8.8.8.8:53"
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+	return "8.8.8.8:53"
+}
+
+// Resolve queries the A record of host and follows the CNAMEs in the
+// answer. When the last name has no A record, its AAAA records are queried
+// too, so an IPv6-only target is not mistaken for a dangling one.
+func (r *DNSResolver) Resolve(ctx context.Context, host string) (*DNSAnswer, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	msg, err := r.query(ctx, name, dnsmessage.TypeA)
+	if err != nil {
+		return nil, err
+	}
+	ans := parseAnswer(msg, name.String())
+	if ans.NXDomain || len(ans.Addresses) > 0 {
+		return ans, nil
+	}
+	msg, err = r.query(ctx, name, dnsmessage.TypeAAAA)
+	if err != nil {
+		return nil, err
+	}
+	if v6 := parseAnswer(msg, name.String()); len(v6.Addresses) > 0 {
+		ans.Addresses = v6.Addresses
+	}
+	return ans, nil
+}
+
+// query sends one question to the server and returns the matching response.
+func (r *DNSResolver) query(ctx context.Context, name dnsmessage.Name, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	id := uint16(rand.Intn(1 << 16))
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil || msg.ID != id || !msg.Response {
+			continue // Stray or malformed packet
+		}
+		return &msg, nil
+	}
+}
+
+// parseAnswer follows the CNAME chain for qname through the answer section.
+func parseAnswer(msg *dnsmessage.Message, qname string) *DNSAnswer {
+	ans := &DNSAnswer{
+		NXDomain: msg.RCode == dnsmessage.RCodeNameError,
+		RCode:    msg.RCode.String(),
+	}
+	cnames := make(map[string]string)
+	addrs := make(map[string][]string)
+	for _, rr := range msg.Answers {
+		owner := strings.ToLower(rr.Header.Name.String())
+		switch body := rr.Body.(type) {
+		case *dnsmessage.CNAMEResource:
+			cnames[owner] = strings.ToLower(body.CNAME.String())
+		case *dnsmessage.AResource:
+			addrs[owner] = append(addrs[owner], net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			addrs[owner] = append(addrs[owner], net.IP(body.AAAA[:]).String())
+		}
+	}
+
+	current := strings.ToLower(qname)
+	for i := 0; i < maxCNAMEHops; i++ {
+		next, ok := cnames[current]
+		if !ok {
+			break
+		}
+		ans.Chain = append(ans.Chain, strings.TrimSuffix(next, "."))
+		current = next
+	}
+	ans.Addresses = addrs[current]
+	return ans
+}
+
+// String renders the chain for evidence, e.g. "x.github.io (NXDOMAIN)".
+func (a *DNSAnswer) String() string {
+	s := strings.Join(a.Chain, " -> ")
+	switch {
+	case a.NXDomain:
+		s += " (NXDOMAIN)"
+	case len(a.Addresses) == 0:
+		s += fmt.Sprintf(" (no address, %s)", strings.TrimPrefix(a.RCode, "RCode"))
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+package analyzers
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// Endpoint kinds reported by EndpointAnalyzer.
+const (
+	EndpointURL       = "url"       // Absolute URL, usually on another host
+	EndpointPath      = "path"      // Same-host route
+	EndpointAPI       = "api"       // REST-style API route
+	EndpointGraphQL   = "graphql"   // GraphQL endpoint
+	EndpointSourceMap = "sourcemap" // JavaScript source map
+)
+
+// maxEndpoints bounds the endpoints recorded per result.
+const maxEndpoints = 500
+
+var (
+	absoluteURLPattern = regexp.MustCompile(`https?://[A-Za-z0-9.-]+(?::\d+)?(?:/[^\s"'<>` + "`" + `\\)]*)?`)
+	quotedPathPattern  = regexp.MustCompile(`["'` + "`" + `](/[A-Za-z0-9_\-./~%{}:$]*(?:\?[A-Za-z0-9_\-.=&%]*)?)["'` + "`" + `]`)
+	sourceMapPattern   = regexp.MustCompile(`//[#@]\s*sourceMappingURL=(\S+)`)
+	apiPathPattern     = regexp.MustCompile(`(?i)(?:^|/)(?:api|rest|rpc|v\d+)(?:/|$)|\.json$`)
+	routeParamPattern  = regexp.MustCompile(`[{}$]|/:`)
+
+	// Static assets and namespace URLs are not worth reporting.
+	staticExts = map[string]bool{
+		".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true,
+		".webp": true, ".css": true, ".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+		".mp4": true, ".webm": true, ".mp3": true,
+	}
+	ignoredHosts = []string{"www.w3.org", "w3.org", "schema.org", "json-schema.org"}
+
+	// Extensions of pages worth following as new scan targets.
+	pageExts = map[string]bool{
+		"": true, ".html": true, ".htm": true, ".php": true, ".asp": true, ".aspx": true,
+		".jsp": true, ".do": true, ".action": true, ".cgi": true,
+	}
+)
+
+// EndpointAnalyzer extracts URLs, API routes, GraphQL endpoints and source-map
+// references from the page and from the scripts gathered by ScriptCollector.
+type EndpointAnalyzer struct{}
+
+// NewEndpointAnalyzer creates a new endpoint extractor.
+func NewEndpointAnalyzer() *EndpointAnalyzer {
+	return &EndpointAnalyzer{}
+}
+
+// Name returns the analyzer name.
+func (a *EndpointAnalyzer) Name() string {
+	return "Endpoint Extraction"
+}
+
+// Analyze sets result.Endpoints and suggests same-host page routes as
+// result.Discovered targets.
+func (a *EndpointAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive {
+		return nil
+	}
+	page, err := url.Parse(result.Target.URL)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	add := func(e domain.Endpoint) {
+		if seen[e.URL] || len(result.Endpoints) >= maxEndpoints {
+			return
+		}
+		seen[e.URL] = true
+		result.Endpoints = append(result.Endpoints, e)
+	}
+
+	for _, e := range ExtractEndpoints(result.Metadata.Body, page, page) {
+		add(e)
+	}
+	for _, script := range result.Metadata.ScriptSources {
+		src, err := url.Parse(script.URL)
+		if err != nil {
+			continue
+		}
+		for _, e := range ExtractEndpoints(script.Body, page, src) {
+			add(e)
+		}
+	}
+
+	discovered := make(map[string]bool)
+	for _, e := range result.Endpoints {
+		if e.Kind != EndpointPath || discovered[e.URL] || e.URL == result.Target.URL {
+			continue
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil || !sameOrigin(page, u) || !pageExts[strings.ToLower(path.Ext(u.Path))] {
+			continue
+		}
+		discovered[e.URL] = true
+		result.Discovered = append(result.Discovered, domain.Target{
+			URL:      e.URL,
+			IP:       result.Target.IP,
+			Port:     result.Target.Port,
+			Protocol: result.Target.Protocol,
+		})
+	}
+	return nil
+}
+
+// ExtractEndpoints finds endpoints in content. Relative paths are resolved
+// against page; source maps against source, the document they came from.
+func ExtractEndpoints(content string, page, source *url.URL) []domain.Endpoint {
+	var endpoints []domain.Endpoint
+	origin := source.String()
+
+	for _, m := range sourceMapPattern.FindAllStringSubmatch(content, -1) {
+		ref := strings.TrimRight(m[1], `*/;"'`)
+		if strings.HasPrefix(ref, "data:") {
+			continue
+		}
+		if u, err := source.Parse(ref); err == nil {
+			endpoints = append(endpoints, domain.Endpoint{URL: u.String(), Kind: EndpointSourceMap, Source: origin})
+		}
+	}
+
+	for _, raw := range absoluteURLPattern.FindAllString(content, -1) {
+		u, err := url.Parse(strings.TrimRight(raw, ".,;:"))
+		if err != nil || ignoredHost(u.Hostname()) || staticExts[strings.ToLower(path.Ext(u.Path))] {
+			continue
+		}
+		kind := EndpointURL
+		if sameOrigin(page, u) {
+			kind = classifyPath(u.Path)
+		}
+		endpoints = append(endpoints, domain.Endpoint{URL: u.String(), Kind: kind, Source: origin})
+	}
+
+	for _, m := range quotedPathPattern.FindAllStringSubmatch(content, -1) {
+		ref := m[1]
+		if len(ref) < 2 || strings.HasPrefix(ref, "//") || staticExts[strings.ToLower(path.Ext(ref))] {
+			continue
+		}
+		u, err := page.Parse(ref)
+		if err != nil {
+			continue
+		}
+		endpoints = append(endpoints, domain.Endpoint{URL: u.String(), Kind: classifyPath(ref), Source: origin})
+	}
+
+	return endpoints
+}
+
+// classifyPath decides whether a same-host path is an API or GraphQL route.
+func classifyPath(p string) string {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.Contains(lower, "graphql") || strings.HasSuffix(lower, "/gql"):
+		return EndpointGraphQL
+	case apiPathPattern.MatchString(lower):
+		return EndpointAPI
+	case routeParamPattern.MatchString(p):
+		// Templated routes such as /users/{id} are reported as API routes
+		// since they cannot be requested as-is.
+		return EndpointAPI
+	default:
+		return EndpointPath
+	}
+}
+
+func ignoredHost(host string) bool {
+	return equalsAny(strings.ToLower(host), ignoredHosts)
+}
@@ -0,0 +1,66 @@
+package analyzers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+func TestEndpointAnalyzer(t *testing.T) {
+	script := `fetch("/api/v1/users");
+const gql = "https://app.example.com/graphql";
+const cdn = 'https://cdn.other.com/sdk/init';
+const logo = "/static/logo.png";
+const ns = "http://www.w3.org/2000/svg";
+router.push('/settings/profile');
+get(` + "`/users/${id}`" + `);
+//# sourceMappingURL=main.js.map`
+
+	res := &domain.ScanResult{
+		Target:  domain.Target{URL: "https://app.example.com/"},
+		IsAlive: true,
+		Metadata: domain.Metadata{
+			Body:          `<a href="/about">About</a><script>var x = "/login.php"</script>`,
+			ScriptSources: []domain.ScriptSource{{URL: "https://app.example.com/assets/main.js", Body: script}},
+		},
+	}
+	if err := NewEndpointAnalyzer().Analyze(context.Background(), res); err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := make(map[string]string)
+	for _, e := range res.Endpoints {
+		kinds[e.URL] = e.Kind
+	}
+	want := map[string]string{
+		"https://app.example.com/api/v1/users":       EndpointAPI,
+		"https://app.example.com/graphql":            EndpointGraphQL,
+		"https://cdn.other.com/sdk/init":             EndpointURL,
+		"https://app.example.com/settings/profile":   EndpointPath,
+		"https://app.example.com/login.php":          EndpointPath,
+		"https://app.example.com/assets/main.js.map": EndpointSourceMap,
+		"https://app.example.com/users/$%7Bid%7D":    EndpointAPI,
+	}
+	for u, kind := range want {
+		if kinds[u] != kind {
+			t.Errorf("%s: kind = %q, want %q", u, kinds[u], kind)
+		}
+	}
+	for _, skipped := range []string{"https://app.example.com/static/logo.png", "http://www.w3.org/2000/svg"} {
+		if _, ok := kinds[skipped]; ok {
+			t.Errorf("%s should have been ignored", skipped)
+		}
+	}
+
+	discovered := make(map[string]bool)
+	for _, d := range res.Discovered {
+		discovered[d.URL] = true
+	}
+	if !discovered["https://app.example.com/settings/profile"] || !discovered["https://app.example.com/login.php"] {
+		t.Errorf("same-host pages not discovered: %+v", res.Discovered)
+	}
+	if discovered["https://cdn.other.com/sdk/init"] || discovered["https://app.example.com/api/v1/users"] {
+		t.Errorf("off-host or API endpoints must not be followed: %+v", res.Discovered)
+	}
+}
@@ -0,0 +1,237 @@
+package analyzers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// headersAnalyzerName identifies findings produced by HeadersAnalyzer.
+const headersAnalyzerName = "Security Headers"
+
+// minHSTSMaxAge is the smallest HSTS max-age (180 days) not reported as weak.
+const minHSTSMaxAge = 15552000
+
+// severityPenalty is the number of points a finding removes from the score.
+var severityPenalty = map[domain.Severity]int{
+	domain.SeverityInfo:     0,
+	domain.SeverityLow:      4,
+	domain.SeverityMedium:   10,
+	domain.SeverityHigh:     25,
+	domain.SeverityCritical: 40,
+}
+
+var (
+	versionPattern = regexp.MustCompile(`\d+\.\d+`)
+	sessionCookie  = regexp.MustCompile(`(?i)sess|sid|auth|token|jwt|login`)
+)
+
+// HeadersAnalyzer evaluates the security headers and cookies of a response
+// and scores the host's posture.
+type HeadersAnalyzer struct{}
+
+// NewHeadersAnalyzer creates a new security header analyzer.
+func NewHeadersAnalyzer() *HeadersAnalyzer {
+	return &HeadersAnalyzer{}
+}
+
+// Name returns the analyzer name.
+func (a *HeadersAnalyzer) Name() string {
+	return headersAnalyzerName
+}
+
+// Analyze appends header and cookie findings to result and sets its
+// SecurityScore and SecurityGrade.
+func (a *HeadersAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive {
+		return nil
+	}
+	findings := CheckHeaders(result)
+	result.Findings = append(result.Findings, findings...)
+	result.SecurityScore = Score(findings)
+	result.SecurityGrade = Grade(result.SecurityScore)
+	return nil
+}
+
+// CheckHeaders returns the header and cookie findings for a result.
+func CheckHeaders(result *domain.ScanResult) []domain.Finding {
+	md := &result.Metadata
+	https := strings.HasPrefix(strings.ToLower(result.Target.URL), "https://")
+	isPage := strings.Contains(strings.ToLower(header(md, "Content-Type")), "html")
+
+	var findings []domain.Finding
+	add := func(id, title string, sev domain.Severity, evidence string) {
+		findings = append(findings, domain.Finding{
+			Analyzer: headersAnalyzerName,
+			ID:       id,
+			Title:    title,
+			Severity: sev,
+			Evidence: evidence,
+		})
+	}
+
+	// Transport
+	if https {
+		hsts := header(md, "Strict-Transport-Security")
+		if hsts == "" {
+			add("missing-hsts", "Strict-Transport-Security header is missing", domain.SeverityMedium, "")
+		} else if age, ok := directiveInt(hsts, "max-age"); !ok || age < minHSTSMaxAge {
+			add("weak-hsts", "Strict-Transport-Security max-age is shorter than 180 days", domain.SeverityLow, hsts)
+		}
+	}
+
+	// Content and framing policies only matter for documents a browser renders.
+	if isPage {
+		csp := header(md, "Content-Security-Policy")
+		if csp == "" {
+			add("missing-csp", "Content-Security-Policy header is missing", domain.SeverityMedium, "")
+		} else {
+			scripts := cspDirective(csp, "script-src")
+			if scripts == "" {
+				scripts = cspDirective(csp, "default-src")
+			}
+			if strings.Contains(scripts, "'unsafe-inline'") {
+				add("csp-unsafe-inline", "Content-Security-Policy allows inline scripts", domain.SeverityLow, scripts)
+			}
+			if strings.Contains(scripts, "'unsafe-eval'") {
+				add("csp-unsafe-eval", "Content-Security-Policy allows eval", domain.SeverityLow, scripts)
+			}
+		}
+
+		if header(md, "X-Frame-Options") == "" && cspDirective(csp, "frame-ancestors") == "" {
+			add("missing-frame-options", "Neither X-Frame-Options nor CSP frame-ancestors restrict framing", domain.SeverityMedium, "")
+		}
+
+		switch rp := strings.ToLower(header(md, "Referrer-Policy")); {
+		case rp == "":
+			add("missing-referrer-policy", "Referrer-Policy header is missing", domain.SeverityLow, "")
+		case strings.Contains(rp, "unsafe-url"):
+			add("weak-referrer-policy", "Referrer-Policy leaks full URLs to other origins", domain.SeverityLow, rp)
+		}
+
+		if header(md, "Permissions-Policy") == "" {
+			add("missing-permissions-policy", "Permissions-Policy header is missing", domain.SeverityInfo, "")
+		}
+		if header(md, "Cross-Origin-Opener-Policy") == "" {
+			add("missing-coop", "Cross-Origin-Opener-Policy header is missing", domain.SeverityInfo, "")
+		}
+		if header(md, "Cross-Origin-Embedder-Policy") == "" {
+			add("missing-coep", "Cross-Origin-Embedder-Policy header is missing", domain.SeverityInfo, "")
+		}
+	}
+
+	if !strings.EqualFold(header(md, "X-Content-Type-Options"), "nosniff") {
+		add("missing-nosniff", "X-Content-Type-Options is not set to nosniff", domain.SeverityLow, "")
+	}
+
+	// CORS
+	origin := strings.TrimSpace(header(md, "Access-Control-Allow-Origin"))
+	credentials := strings.EqualFold(strings.TrimSpace(header(md, "Access-Control-Allow-Credentials")), "true")
+	switch {
+	case origin == "*" && credentials:
+		add("cors-wildcard-credentials", "CORS allows any origin together with credentials", domain.SeverityHigh, "Access-Control-Allow-Origin: *")
+	case strings.EqualFold(origin, "null"):
+		add("cors-null-origin", "CORS trusts the null origin", domain.SeverityMedium, "Access-Control-Allow-Origin: null")
+	case origin == "*":
+		add("cors-wildcard", "CORS allows any origin", domain.SeverityInfo, "Access-Control-Allow-Origin: *")
+	}
+
+	// Version disclosure
+	if server := header(md, "Server"); versionPattern.MatchString(server) {
+		add("server-version", "Server header discloses a version", domain.SeverityLow, server)
+	}
+	for _, h := range []string{"X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version", "X-Generator"} {
+		if v := header(md, h); v != "" {
+			add("header-disclosure", h+" header discloses the software stack", domain.SeverityLow, h+": "+v)
+		}
+	}
+
+	// Cookies
+	for _, raw := range md.Cookies {
+		c, err := http.ParseSetCookie(raw)
+		if err != nil {
+			continue
+		}
+		attrs := strings.ToLower(raw)
+		sensitive := sessionCookie.MatchString(c.Name)
+		if https && !c.Secure {
+			sev := domain.SeverityLow
+			if sensitive {
+				sev = domain.SeverityMedium
+			}
+			add("cookie-no-secure", "Cookie "+c.Name+" is missing the Secure flag", sev, c.Name)
+		}
+		if !c.HttpOnly {
+			sev := domain.SeverityInfo
+			if sensitive {
+				sev = domain.SeverityMedium
+			}
+			add("cookie-no-httponly", "Cookie "+c.Name+" is missing the HttpOnly flag", sev, c.Name)
+		}
+		if !strings.Contains(attrs, "samesite") {
+			add("cookie-no-samesite", "Cookie "+c.Name+" has no SameSite attribute", domain.SeverityLow, c.Name)
+		} else if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+			add("cookie-samesite-none-insecure", "Cookie "+c.Name+" uses SameSite=None without Secure", domain.SeverityMedium, c.Name)
+		}
+	}
+
+	return findings
+}
+
+// Score converts findings into a 0-100 posture score.
+func Score(findings []domain.Finding) int {
+	score := 100
+	for _, f := range findings {
+		score -= severityPenalty[f.Severity]
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// Grade maps a posture score to a letter grade.
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 65:
+		return "C"
+	case score >= 50:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// cspDirective returns the value of a CSP directive, or "" if absent.
+func cspDirective(csp, name string) string {
+	for _, part := range strings.Split(csp, ";") {
+		fields := strings.Fields(part)
+		if len(fields) > 0 && strings.EqualFold(fields[0], name) {
+			if len(fields) == 1 {
+				return name // Present but empty, e.g. "frame-ancestors"
+			}
+			return strings.Join(fields[1:], " ")
+		}
+	}
+	return ""
+}
+
+// directiveInt parses a "name=value" integer directive from a header value.
+func directiveInt(value, name string) (int, bool) {
+	for _, part := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(v), `"`))
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
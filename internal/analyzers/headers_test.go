@@ -0,0 +1,107 @@
+package analyzers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+func findingIDs(findings []domain.Finding) map[string]domain.Severity {
+	ids := make(map[string]domain.Severity)
+	for _, f := range findings {
+		ids[f.ID] = f.Severity
+	}
+	return ids
+}
+
+func TestCheckHeadersHardenedSite(t *testing.T) {
+	res := &domain.ScanResult{
+		Target:  domain.Target{URL: "https://secure.example.com/"},
+		IsAlive: true,
+		Metadata: domain.Metadata{
+			Headers: map[string]string{
+				"Content-Type":                 "text/html; charset=utf-8",
+				"Strict-Transport-Security":    "max-age=31536000; includeSubDomains",
+				"Content-Security-Policy":      "default-src 'self'; frame-ancestors 'none'",
+				"Referrer-Policy":              "strict-origin-when-cross-origin",
+				"Permissions-Policy":           "camera=()",
+				"Cross-Origin-Opener-Policy":   "same-origin",
+				"Cross-Origin-Embedder-Policy": "require-corp",
+				"X-Content-Type-Options":       "nosniff",
+				"Server":                       "nginx",
+			},
+			Cookies: []string{"session=abc; Path=/; Secure; HttpOnly; SameSite=Lax; Expires=Wed, 21 Oct 2026 07:28:00 GMT"},
+		},
+	}
+
+	if err := NewHeadersAnalyzer().Analyze(context.Background(), res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Findings) != 0 {
+		t.Errorf("expected no findings, got %+v", res.Findings)
+	}
+	if res.SecurityScore != 100 || res.SecurityGrade != "A" {
+		t.Errorf("score = %d grade = %q, want 100 A", res.SecurityScore, res.SecurityGrade)
+	}
+}
+
+func TestCheckHeadersWeakSite(t *testing.T) {
+	res := &domain.ScanResult{
+		Target:  domain.Target{URL: "https://weak.example.com/"},
+		IsAlive: true,
+		Metadata: domain.Metadata{
+			Headers: map[string]string{
+				"Content-Type":                     "text/html",
+				"Strict-Transport-Security":        "max-age=300",
+				"Content-Security-Policy":          "script-src 'self' 'unsafe-inline'",
+				"Referrer-Policy":                  "unsafe-url",
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "true",
+				"Server":                           "Apache/2.4.41 (Ubuntu)",
+				"X-Powered-By":                     "PHP/7.4.3",
+			},
+			Cookies: []string{
+				"PHPSESSID=xyz; Path=/",
+				"tracking=1; SameSite=None",
+			},
+		},
+	}
+
+	ids := findingIDs(CheckHeaders(res))
+	for _, id := range []string{
+		"weak-hsts", "csp-unsafe-inline", "missing-frame-options", "weak-referrer-policy",
+		"missing-nosniff", "cors-wildcard-credentials", "server-version", "header-disclosure",
+		"cookie-no-secure", "cookie-no-httponly", "cookie-no-samesite", "cookie-samesite-none-insecure",
+	} {
+		if _, ok := ids[id]; !ok {
+			t.Errorf("missing finding %s", id)
+		}
+	}
+	if ids["cors-wildcard-credentials"] != domain.SeverityHigh {
+		t.Errorf("cors-wildcard-credentials severity = %s, want high", ids["cors-wildcard-credentials"])
+	}
+	if _, ok := ids["missing-hsts"]; ok {
+		t.Error("weak HSTS should not also be reported as missing")
+	}
+}
+
+func TestCheckHeadersSkipsPageChecksForAPIs(t *testing.T) {
+	res := &domain.ScanResult{
+		Target: domain.Target{URL: "http://api.example.com/"},
+		Metadata: domain.Metadata{
+			Headers: map[string]string{"Content-Type": "application/json", "X-Content-Type-Options": "nosniff"},
+		},
+	}
+	if findings := CheckHeaders(res); len(findings) != 0 {
+		t.Errorf("expected no findings for a plain-HTTP JSON response, got %+v", findings)
+	}
+}
+
+func TestGrade(t *testing.T) {
+	for score, want := range map[int]string{100: "A", 90: "A", 85: "B", 70: "C", 55: "D", 10: "F"} {
+		if got := Grade(score); got != want {
+			t.Errorf("Grade(%d) = %q, want %q", score, got, want)
+		}
+	}
+}
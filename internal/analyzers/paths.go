@@ -0,0 +1,262 @@
+package analyzers
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/core/ports"
+)
+
+// pathsAnalyzerName identifies findings produced by PathProber.
+const pathsAnalyzerName = "Path Probe"
+
+const (
+	maxPathBody        = 256 * 1024 // Bytes read per probed path
+	maxPathScreenshots = 5          // Interesting hits captured per host
+)
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// loginPathPattern matches redirect targets that ask the client to sign in.
+var loginPathPattern = regexp.MustCompile(`(?i)log-?in|sign-?in|auth|sso`)
+
+// ProbePath is a path requested on every host.
+type ProbePath struct {
+	Path     string
+	Expect   string          // Case-insensitive marker a genuine hit contains; empty accepts any body
+	Finding  string          // Finding ID reported for a genuine hit, if any
+	Title    string          // Finding title
+	Severity domain.Severity // Finding severity
+}
+
+// DefaultProbePaths are always requested when path probing is enabled.
+var DefaultProbePaths = []ProbePath{
+	{Path: "/robots.txt", Expect: "user-agent"},
+	{Path: "/sitemap.xml", Expect: "sitemaps.org"},
+	{Path: "/.well-known/security.txt", Expect: "contact:"},
+	{Path: "/.git/HEAD", Expect: "ref: refs/", Finding: "exposed-git", Title: "Git repository metadata is exposed", Severity: domain.SeverityHigh},
+	{Path: "/server-status", Expect: "server status", Finding: "exposed-server-status", Title: "Apache server-status page is exposed", Severity: domain.SeverityMedium},
+}
+
+// LoadWordlist reads one path per line, ignoring blank lines and # comments.
+func LoadWordlist(path string) ([]ProbePath, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var paths []ProbePath
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		paths = append(paths, ProbePath{Path: line})
+	}
+	return paths, scanner.Err()
+}
+
+// PathProber requests a list of well-known and user-supplied paths on each
+// alive host, discards soft-404s and optionally screenshots the hits.
+type PathProber struct {
+	client   *http.Client
+	paths    []ProbePath
+	renderer ports.Renderer // Optional
+
+	mu     sync.Mutex
+	probed map[string]bool // Origins already probed
+}
+
+// NewPathProber creates a path prober. Redirects are not followed, so that a
+// path redirecting to a login page or to the site root can be told apart and
+// dropped. renderer may be nil.
+func NewPathProber(client *http.Client, paths []ProbePath, renderer ports.Renderer) *PathProber {
+	c := *client
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &PathProber{
+		client:   &c,
+		paths:    paths,
+		renderer: renderer,
+		probed:   make(map[string]bool),
+	}
+}
+
+// Name returns the analyzer name.
+func (p *PathProber) Name() string {
+	return pathsAnalyzerName
+}
+
+// pathResponse is what is compared against the soft-404 baseline.
+type pathResponse struct {
+	status   int
+	length   int64
+	title    string
+	body     string
+	location string // Path of the redirect target, if any
+}
+
+// Analyze probes each origin once and records the genuine hits on the first
+// result seen for it.
+func (p *PathProber) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive || len(p.paths) == 0 {
+		return nil
+	}
+	page, err := url.Parse(result.Target.URL)
+	if err != nil {
+		return nil
+	}
+	origin := page.Scheme + "://" + page.Host
+
+	p.mu.Lock()
+	if p.probed[origin] {
+		p.mu.Unlock()
+		return nil
+	}
+	p.probed[origin] = true
+	p.mu.Unlock()
+
+	baseline, err := p.fetch(ctx, origin+"/"+randomPath())
+	if err != nil {
+		return err
+	}
+
+	shots := 0
+	for _, pp := range p.paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		target := origin + pp.Path
+		resp, err := p.fetch(ctx, target)
+		if err != nil || !isHit(resp, baseline, pp) {
+			continue
+		}
+
+		hit := domain.PathHit{
+			URL:        target,
+			Path:       pp.Path,
+			StatusCode: resp.status,
+			Length:     resp.length,
+			Title:      resp.title,
+		}
+		if p.renderer != nil && resp.status < 300 && shots < maxPathScreenshots {
+			if rendered, err := p.renderer.Render(ctx, domain.Target{URL: target}); err == nil {
+				hit.Screenshot = rendered.Path
+				hit.Thumbnail = rendered.Thumbnail
+				shots++
+			}
+		}
+		result.Paths = append(result.Paths, hit)
+
+		if pp.Finding != "" {
+			result.Findings = append(result.Findings, domain.Finding{
+				Analyzer: pathsAnalyzerName,
+				ID:       pp.Finding,
+				Title:    pp.Title,
+				Severity: pp.Severity,
+				Evidence: firstLine(resp.body),
+				Location: target,
+			})
+		}
+	}
+	return nil
+}
+
+func (p *PathProber) fetch(ctx context.Context, target string) (*pathResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxPathBody))
+	length := resp.ContentLength
+	if length < 0 {
+		length = int64(len(data))
+	}
+	body := string(data)
+	title := ""
+	if m := titlePattern.FindStringSubmatch(body); m != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(m[1])), " ")
+	}
+	location := ""
+	if loc, err := resp.Location(); err == nil {
+		location = loc.Path
+	}
+	return &pathResponse{status: resp.StatusCode, length: length, title: title, body: body, location: location}, nil
+}
+
+// isHit decides whether a response is a genuine hit rather than a 404, a
+// redirect to a login page or the site root, or a catch-all page that looks
+// like the random-path baseline.
+func isHit(resp, baseline *pathResponse, pp ProbePath) bool {
+	if resp.status == http.StatusNotFound || resp.status == http.StatusGone || resp.status >= 500 {
+		return false
+	}
+	if resp.status >= 300 && resp.status < 400 {
+		if resp.location == "" || resp.location == "/" || loginPathPattern.MatchString(resp.location) {
+			return false
+		}
+	}
+	if pp.Expect != "" {
+		return resp.status < 300 && strings.Contains(strings.ToLower(resp.body), pp.Expect)
+	}
+	if resp.status != baseline.status {
+		return true
+	}
+	if resp.title != "" && resp.title == baseline.title {
+		return false
+	}
+	return !similarLength(resp.length, baseline.length)
+}
+
+// similarLength reports whether two lengths differ by at most 10%.
+func similarLength(a, b int64) bool {
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+	max := a
+	if b > max {
+		max = b
+	}
+	return max == 0 || diff*10 <= max
+}
+
+func randomPath() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "netvista-" + hex.EncodeToString(buf)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if len(s) > 120 {
+		s = s[:120]
+	}
+	return strings.ToValidUTF8(s, "")
+}
@@ -0,0 +1,85 @@
+package analyzers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+type stubRenderer struct{ rendered []string }
+
+func (r *stubRenderer) Render(ctx context.Context, t domain.Target) (*domain.RenderResult, error) {
+	r.rendered = append(r.rendered, t.URL)
+	return &domain.RenderResult{Path: "/tmp/" + t.URL}, nil
+}
+
+func (r *stubRenderer) Close() error { return nil }
+
+func TestPathProber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+		case "/.git/HEAD":
+			w.Write([]byte("ref: refs/heads/main\n"))
+		case "/sitemap.xml":
+			// Catch-all HTML instead of a sitemap
+			w.Write([]byte("<html><title>Shop</title>home page</html>"))
+		case "/backup.zip":
+			w.Write([]byte("PK\x03\x04 a real archive with quite a different size from the catch-all page"))
+		case "/old":
+			http.Redirect(w, r, "/login?next=/old", http.StatusFound)
+		case "/dashboard":
+			http.Redirect(w, r, "/", http.StatusFound)
+		case "/docs":
+			http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+		default:
+			// Soft 404: every unknown path returns the home page
+			w.Write([]byte("<html><title>Shop</title>home page</html>"))
+		}
+	}))
+	defer srv.Close()
+
+	paths := append(append([]ProbePath{}, DefaultProbePaths...), ProbePath{Path: "/backup.zip"}, ProbePath{Path: "/admin"},
+		ProbePath{Path: "/old"}, ProbePath{Path: "/dashboard"}, ProbePath{Path: "/docs"})
+	renderer := &stubRenderer{}
+	prober := NewPathProber(NewHTTPClient(5*time.Second, "", nil), paths, renderer)
+
+	res := &domain.ScanResult{Target: domain.Target{URL: srv.URL + "/"}, IsAlive: true}
+	if err := prober.Analyze(context.Background(), res); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := make(map[string]int)
+	for _, h := range res.Paths {
+		hits[h.Path] = h.StatusCode
+	}
+	want := map[string]int{"/robots.txt": 200, "/.git/HEAD": 200, "/backup.zip": 200, "/docs": http.StatusMovedPermanently}
+	if len(hits) != len(want) {
+		t.Errorf("hits = %v, want %v", hits, want)
+	}
+	for p, status := range want {
+		if hits[p] != status {
+			t.Errorf("%s: status %d, want %d", p, hits[p], status)
+		}
+	}
+	if len(renderer.rendered) != 3 {
+		t.Errorf("rendered %v, want the three 2xx hits", renderer.rendered)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].ID != "exposed-git" {
+		t.Errorf("findings = %+v, want exposed-git", res.Findings)
+	}
+
+	// A second result on the same origin is not probed again.
+	again := &domain.ScanResult{Target: domain.Target{URL: srv.URL + "/other"}, IsAlive: true}
+	if err := prober.Analyze(context.Background(), again); err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Paths) != 0 {
+		t.Errorf("origin probed twice: %+v", again.Paths)
+	}
+}
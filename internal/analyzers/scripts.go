@@ -0,0 +1,174 @@
+package analyzers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Default bounds for script collection.
+const (
+	DefaultMaxScripts    = 20
+	DefaultMaxScriptSize = 2 * 1024 * 1024
+)
+
+// NewHTTPClient returns a client for the requests analyzers make on their own,
+// honouring the scan's timeout, proxy and custom headers.
+func NewHTTPClient(timeout time.Duration, proxyURL string, headers map[string]string) *http.Client {
+	transport := &http.Transport{}
+	if proxyURL != "" {
+		if proxy, err := url.Parse(proxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxy)
+		}
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &headerTransport{base: transport, headers: headers},
+	}
+}
+
+// headerTransport adds the configured custom headers to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) > 0 {
+		req = req.Clone(req.Context())
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// ScriptScope limits the hosts scripts are fetched from. The zero value
+// allows any host, since bundles and source maps often live on a CDN or a
+// sibling subdomain.
+type ScriptScope struct {
+	SameSite bool     // Only fetch from the page's registrable domain and Domains
+	Domains  []string // Further domains allowed with their subdomains; implies SameSite
+}
+
+// allows reports whether a script at u may be fetched for page.
+func (s ScriptScope) allows(page, u *url.URL) bool {
+	if !s.SameSite && len(s.Domains) == 0 {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	site, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(page.Hostname()))
+	if err != nil {
+		site = strings.ToLower(page.Hostname())
+	}
+	for _, d := range append([]string{site}, s.Domains...) {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScriptCollector downloads the scripts referenced by a page, from any host
+// its scope allows, so later analyzers can inspect them. It must run before
+// those analyzers.
+type ScriptCollector struct {
+	client     *http.Client
+	maxScripts int
+	maxSize    int64
+	scope      ScriptScope
+}
+
+// NewScriptCollector creates a collector fetching at most maxScripts scripts
+// of at most maxSize bytes each from the hosts scope allows. Non-positive
+// limits use the defaults.
+func NewScriptCollector(client *http.Client, maxScripts int, maxSize int64, scope ScriptScope) *ScriptCollector {
+	if maxScripts <= 0 {
+		maxScripts = DefaultMaxScripts
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxScriptSize
+	}
+	return &ScriptCollector{client: client, maxScripts: maxScripts, maxSize: maxSize, scope: scope}
+}
+
+// Name returns the analyzer name.
+func (c *ScriptCollector) Name() string {
+	return "Script Collector"
+}
+
+// Analyze fills result.Metadata.ScriptSources. Scripts that fail to download
+// are skipped.
+func (c *ScriptCollector) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive || len(result.Metadata.Scripts) == 0 {
+		return nil
+	}
+	page, err := url.Parse(result.Target.URL)
+	if err != nil {
+		return nil
+	}
+
+	for _, src := range result.Metadata.Scripts {
+		if len(result.Metadata.ScriptSources) >= c.maxScripts {
+			break
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		u, err := url.Parse(src)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !c.scope.allows(page, u) {
+			continue
+		}
+		body, err := c.fetch(ctx, u.String())
+		if err != nil {
+			continue
+		}
+		result.Metadata.ScriptSources = append(result.Metadata.ScriptSources, domain.ScriptSource{URL: u.String(), Body: body})
+	}
+	return nil
+}
+
+func (c *ScriptCollector) fetch(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxSize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sameOrigin reports whether two URLs share scheme, host and port.
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		originPort(a) == originPort(b)
+}
+
+func originPort(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	if strings.EqualFold(u.Scheme, "https") {
+		return "443"
+	}
+	return "80"
+}
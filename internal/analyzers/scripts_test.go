@@ -0,0 +1,32 @@
+package analyzers
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestScriptScope(t *testing.T) {
+	page, _ := url.Parse("https://www.example.co.uk/login")
+	tests := []struct {
+		name   string
+		scope  ScriptScope
+		script string
+		want   bool
+	}{
+		{"any host by default", ScriptScope{}, "https://cdn.jsdelivr.net/npm/app.js", true},
+		{"sibling subdomain", ScriptScope{SameSite: true}, "https://static.example.co.uk/app.js", true},
+		{"registrable domain itself", ScriptScope{SameSite: true}, "https://example.co.uk/app.js", true},
+		{"other domain under the same suffix", ScriptScope{SameSite: true}, "https://other.co.uk/app.js", false},
+		{"allowlisted CDN", ScriptScope{Domains: []string{"jsdelivr.net"}}, "https://cdn.jsdelivr.net/npm/app.js", true},
+		{"lookalike of an allowlisted CDN", ScriptScope{Domains: []string{"jsdelivr.net"}}, "https://evil-jsdelivr.net/app.js", false},
+		{"allowlist keeps the page's site", ScriptScope{Domains: []string{"jsdelivr.net"}}, "https://assets.example.co.uk/app.js", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.script)
+			if got := tt.scope.allows(page, u); got != tt.want {
+				t.Errorf("allows(%s) = %v, want %v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package analyzers
+
+import (
+	"context"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/secrets"
+)
+
+// secretsAnalyzerName identifies findings produced by SecretsAnalyzer.
+const secretsAnalyzerName = "Secrets"
+
+// SecretsAnalyzer looks for credentials, keys, stack traces and debug pages in
+// the response body and in scripts gathered by ScriptCollector.
+type SecretsAnalyzer struct {
+	rules *secrets.Rules
+}
+
+// NewSecretsAnalyzer creates a secrets analyzer using the given rule set.
+func NewSecretsAnalyzer(rules *secrets.Rules) *SecretsAnalyzer {
+	return &SecretsAnalyzer{rules: rules}
+}
+
+// Name returns the analyzer name.
+func (a *SecretsAnalyzer) Name() string {
+	return secretsAnalyzerName
+}
+
+// Analyze appends one finding per match. Secret values are redacted.
+func (a *SecretsAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive || a.rules == nil {
+		return nil
+	}
+
+	a.scan(result, result.Target.URL, result.Metadata.Body, secrets.ScopeBody)
+	for _, script := range result.Metadata.ScriptSources {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		a.scan(result, script.URL, script.Body, secrets.ScopeScript)
+	}
+	return nil
+}
+
+func (a *SecretsAnalyzer) scan(result *domain.ScanResult, location, content, scope string) {
+	for _, m := range a.rules.Scan(content, scope) {
+		result.Findings = append(result.Findings, domain.Finding{
+			Analyzer: secretsAnalyzerName,
+			ID:       m.Rule.ID,
+			Title:    m.Rule.Name,
+			Severity: domain.Severity(m.Rule.Severity),
+			Evidence: m.Context,
+			Location: location,
+			Line:     m.Line,
+			Offset:   m.Offset,
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package analyzers
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/signatures"
+)
+
+// takeoverAnalyzerName identifies findings produced by TakeoverAnalyzer.
+const takeoverAnalyzerName = "Takeover Detection"
+
+// Finding IDs reported by TakeoverAnalyzer.
+const (
+	FindingTakeover      = "subdomain-takeover"
+	FindingDanglingCNAME = "dangling-cname"
+)
+
+// Confidence levels.
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+// TakeoverAnalyzer flags hostnames that can likely be claimed on a
+// third-party service, combining the CNAME chain, the DNS response code and
+// the service's "unclaimed" page fingerprint.
+type TakeoverAnalyzer struct {
+	resolver Resolver
+	sigs     []signatures.TakeoverSig
+
+	mu    sync.Mutex
+	cache map[string]*dnsResult // By hostname; several ports share one lookup
+}
+
+// dnsResult is a lookup shared by the targets on one hostname. Done is
+// closed once answer or err is set.
+type dnsResult struct {
+	done   chan struct{}
+	answer *DNSAnswer
+	err    error
+}
+
+// NewTakeoverAnalyzer creates a takeover analyzer.
+func NewTakeoverAnalyzer(resolver Resolver, sigs []signatures.TakeoverSig) *TakeoverAnalyzer {
+	return &TakeoverAnalyzer{
+		resolver: resolver,
+		sigs:     sigs,
+		cache:    make(map[string]*dnsResult),
+	}
+}
+
+// Name returns the analyzer name.
+func (a *TakeoverAnalyzer) Name() string {
+	return takeoverAnalyzerName
+}
+
+// Analyze resolves the target's hostname and records its CNAME chain and any
+// takeover finding. Unlike most analyzers it also runs for hosts that did not
+// respond, since a dangling CNAME often makes a host unreachable.
+func (a *TakeoverAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	host := hostname(result.Target.URL)
+	if host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	answer, err := a.resolve(ctx, host)
+	if err != nil {
+		return err
+	}
+	result.CNAMEs = answer.Chain
+
+	if f := a.evaluate(host, answer, result); f != nil {
+		result.Findings = append(result.Findings, *f)
+	}
+	return nil
+}
+
+// resolve returns the answer for host, sharing one lookup between the
+// targets on it. The lookup does not stop when the first caller's ctx is
+// cancelled, since others may be waiting for it; the resolver's own timeout
+// bounds it. Only answers are cached, so a failed lookup is retried by the
+// next target on the host.
+func (a *TakeoverAnalyzer) resolve(ctx context.Context, host string) (*DNSAnswer, error) {
+	a.mu.Lock()
+	r, ok := a.cache[host]
+	if !ok {
+		r = &dnsResult{done: make(chan struct{})}
+		a.cache[host] = r
+		go func() {
+			r.answer, r.err = a.resolver.Resolve(context.WithoutCancel(ctx), host)
+			if r.err != nil {
+				a.mu.Lock()
+				if a.cache[host] == r {
+					delete(a.cache, host)
+				}
+				a.mu.Unlock()
+			}
+			close(r.done)
+		}()
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.answer, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// evaluate grades the evidence:
+//   - high: the CNAME points at a known service and either its unclaimed-page
+//     fingerprint is served or, for services claimed by name, the target is
+//     NXDOMAIN
+//   - medium: a known service whose CNAME target no longer resolves, or the
+//     fingerprint behind a CNAME to an unrecognised service
+//   - low: the fingerprint without any CNAME, or a dangling CNAME to an
+//     unrecognised service
+func (a *TakeoverAnalyzer) evaluate(host string, answer *DNSAnswer, result *domain.ScanResult) *domain.Finding {
+	var service, fingerprinted *signatures.TakeoverSig
+	for i := range a.sigs {
+		sig := &a.sigs[i]
+		if service == nil && chainMatches(answer.Chain, sig.CNAME) {
+			service = sig
+		}
+		if fingerprinted == nil && result.IsAlive && sig.Fingerprint != "" && strings.Contains(result.Metadata.Body, sig.Fingerprint) {
+			fingerprinted = sig
+		}
+	}
+
+	evidence := host
+	if len(answer.Chain) > 0 || answer.NXDomain {
+		evidence += " -> " + answer.String()
+	}
+	finding := func(id, name, confidence string) *domain.Finding {
+		sev := domain.SeverityHigh
+		if confidence == ConfidenceLow {
+			sev = domain.SeverityMedium
+		}
+		title := "Possible subdomain takeover"
+		if name != "" {
+			title += " (" + name + ")"
+		}
+		if id == FindingDanglingCNAME {
+			title = "CNAME points to a name that does not resolve"
+		}
+		return &domain.Finding{
+			Analyzer:   takeoverAnalyzerName,
+			ID:         id,
+			Title:      title,
+			Severity:   sev,
+			Confidence: confidence,
+			Evidence:   evidence,
+			Location:   result.Target.URL,
+		}
+	}
+
+	switch {
+	case service != nil:
+		served := result.IsAlive && service.Fingerprint != "" && strings.Contains(result.Metadata.Body, service.Fingerprint)
+		if served || (service.NXDomain && answer.NXDomain) {
+			return finding(FindingTakeover, service.Name, ConfidenceHigh)
+		}
+		if answer.Dangling() {
+			return finding(FindingTakeover, service.Name, ConfidenceMedium)
+		}
+	case fingerprinted != nil && len(answer.Chain) > 0:
+		return finding(FindingTakeover, fingerprinted.Name, ConfidenceMedium)
+	case fingerprinted != nil:
+		return finding(FindingTakeover, fingerprinted.Name, ConfidenceLow)
+	case answer.Dangling() && answer.NXDomain:
+		return finding(FindingDanglingCNAME, "", ConfidenceLow)
+	}
+	return nil
+}
+
+func chainMatches(chain, patterns []string) bool {
+	for _, name := range chain {
+		for _, p := range patterns {
+			if p != "" && strings.Contains(name, strings.ToLower(p)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostname extracts the host from a target URL, which may lack a scheme.
+func hostname(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		u, err = url.Parse("//" + raw)
+		if err != nil {
+			return ""
+		}
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,215 @@
+package analyzers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/signatures"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// stubZone maps a lowercase FQDN to a CNAME target, an A record or an AAAA
+// record.
+type stubZone struct {
+	cnames map[string]string
+	hosts  map[string][4]byte
+	hosts6 map[string][16]byte
+}
+
+// serveStubDNS answers A and AAAA queries from zone on a local UDP socket,
+// following CNAMEs like a recursive resolver and returning NXDOMAIN for
+// unknown names.
+func serveStubDNS(t *testing.T, zone stubZone) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var req dnsmessage.Message
+			if err := req.Unpack(buf[:n]); err != nil || len(req.Questions) != 1 {
+				continue
+			}
+			q := req.Questions[0]
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: req.ID, Response: true, RecursionAvailable: true},
+				Questions: req.Questions,
+			}
+			name := strings.ToLower(q.Name.String())
+			for i := 0; i < 10; i++ {
+				hdr := dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Class: dnsmessage.ClassINET, TTL: 60}
+				if target, ok := zone.cnames[name]; ok {
+					hdr.Type = dnsmessage.TypeCNAME
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)}})
+					name = target
+					continue
+				}
+				ip, v4 := zone.hosts[name]
+				ip6, v6 := zone.hosts6[name]
+				switch {
+				case q.Type == dnsmessage.TypeA && v4:
+					hdr.Type = dnsmessage.TypeA
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AResource{A: ip}})
+				case q.Type == dnsmessage.TypeAAAA && v6:
+					hdr.Type = dnsmessage.TypeAAAA
+					resp.Answers = append(resp.Answers, dnsmessage.Resource{Header: hdr, Body: &dnsmessage.AAAAResource{AAAA: ip6}})
+				case !v4 && !v6:
+					resp.RCode = dnsmessage.RCodeNameError
+				}
+				break
+			}
+			out, err := resp.Pack()
+			if err == nil {
+				conn.WriteTo(out, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestTakeoverAnalyzer(t *testing.T) {
+	server := serveStubDNS(t, stubZone{
+		cnames: map[string]string{
+			"docs.example.com.":   "example.github.io.",
+			"blog.example.com.":   "example.github.io.",
+			"app.example.com.":    "example-app.azurewebsites.net.",
+			"legacy.example.com.": "old-lb.example.net.",
+			"www.example.com.":    "cdn.example.net.",
+			"v6.example.com.":     "example-v6.azurewebsites.net.",
+		},
+		hosts: map[string][4]byte{
+			"example.github.io.": {185, 199, 108, 153},
+			"cdn.example.net.":   {192, 0, 2, 10},
+			"plain.example.com.": {192, 0, 2, 20},
+		},
+		hosts6: map[string][16]byte{
+			"example-v6.azurewebsites.net.": {0x20, 0x01, 0x0d, 0xb8, 15: 1},
+		},
+	})
+	sigs, err := signatures.LoadSignatures("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzer := NewTakeoverAnalyzer(NewDNSResolver(server, 2*time.Second), sigs.Takeovers)
+
+	tests := []struct {
+		name       string
+		url        string
+		alive      bool
+		body       string
+		id         string
+		confidence string
+	}{
+		{"fingerprint behind service CNAME", "https://docs.example.com", true, "There isn't a GitHub Pages site here.", FindingTakeover, ConfidenceHigh},
+		{"service CNAME serving content", "https://blog.example.com", true, "<h1>Our blog</h1>", "", ""},
+		{"NXDOMAIN service", "https://app.example.com", false, "", FindingTakeover, ConfidenceHigh},
+		{"dangling unknown CNAME", "https://legacy.example.com", false, "", FindingDanglingCNAME, ConfidenceLow},
+		{"fingerprint behind unknown CNAME", "https://www.example.com", true, "NoSuchBucket", FindingTakeover, ConfidenceMedium},
+		{"service CNAME to an IPv6-only name", "https://v6.example.com", false, "", "", ""},
+		{"fingerprint without CNAME", "https://plain.example.com", true, "no such app", FindingTakeover, ConfidenceLow},
+		{"IP address", "http://192.0.2.1", true, "no such app", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &domain.ScanResult{
+				Target:   domain.Target{URL: tt.url},
+				IsAlive:  tt.alive,
+				Metadata: domain.Metadata{Body: tt.body},
+			}
+			if err := analyzer.Analyze(context.Background(), res); err != nil {
+				t.Fatal(err)
+			}
+			if tt.id == "" {
+				if len(res.Findings) != 0 {
+					t.Errorf("unexpected findings %+v", res.Findings)
+				}
+				return
+			}
+			if len(res.Findings) != 1 {
+				t.Fatalf("findings = %+v, want one %s", res.Findings, tt.id)
+			}
+			f := res.Findings[0]
+			if f.ID != tt.id || f.Confidence != tt.confidence {
+				t.Errorf("got %s/%s, want %s/%s (%s)", f.ID, f.Confidence, tt.id, tt.confidence, f.Evidence)
+			}
+		})
+	}
+}
+
+func TestDNSResolverChain(t *testing.T) {
+	server := serveStubDNS(t, stubZone{
+		cnames: map[string]string{"a.example.com.": "b.example.net.", "b.example.net.": "c.cdn.test."},
+		hosts:  map[string][4]byte{"c.cdn.test.": {192, 0, 2, 1}},
+	})
+	ans, err := NewDNSResolver(server, 2*time.Second).Resolve(context.Background(), "A.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ans.Chain, ",") != "b.example.net,c.cdn.test" || len(ans.Addresses) != 1 || ans.Dangling() {
+		t.Errorf("unexpected answer %+v", ans)
+	}
+}
+
+// flakyResolver fails its first lookup and then answers with a CNAME to a
+// name that does not resolve.
+type flakyResolver struct {
+	calls atomic.Int32
+}
+
+func (r *flakyResolver) Resolve(ctx context.Context, host string) (*DNSAnswer, error) {
+	if r.calls.Add(1) == 1 {
+		return nil, errors.New("i/o timeout")
+	}
+	return &DNSAnswer{Chain: []string{"gone.example.net"}, NXDomain: true, RCode: "RCodeNameError"}, nil
+}
+
+func TestTakeoverAnalyzerRetriesFailedLookups(t *testing.T) {
+	resolver := &flakyResolver{}
+	analyzer := NewTakeoverAnalyzer(resolver, nil)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := analyzer.Analyze(cancelled, &domain.ScanResult{Target: domain.Target{URL: "https://a.example.com"}}); err == nil {
+		t.Fatal("want an error for a cancelled context")
+	}
+
+	// Whether or not the cancelled caller saw it, the first lookup fails and
+	// must not stay cached.
+	deadline := time.Now().Add(2 * time.Second)
+	for cached := true; cached; {
+		if time.Now().After(deadline) {
+			t.Fatal("failed lookup is still cached")
+		}
+		time.Sleep(time.Millisecond)
+		analyzer.mu.Lock()
+		_, cached = analyzer.cache["a.example.com"]
+		analyzer.mu.Unlock()
+	}
+	for _, port := range []string{"8080", "8443"} {
+		res := &domain.ScanResult{Target: domain.Target{URL: "https://a.example.com:" + port}}
+		if err := analyzer.Analyze(context.Background(), res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Findings) != 1 || res.Findings[0].ID != FindingDanglingCNAME {
+			t.Errorf("findings = %+v, want %s", res.Findings, FindingDanglingCNAME)
+		}
+	}
+	if n := resolver.calls.Load(); n != 2 {
+		t.Errorf("resolver called %d times, want 2 (the answer is cached)", n)
+	}
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import "math/bits"
+
+// Match is a BK-tree search hit.
+type Match struct {
+	Index    int // Index of the matched entry as passed to Insert
+	Distance int
+}
+
+// BKTree indexes values under an integer metric so that all entries within a
+// radius of a query can be found without a full pairwise scan. The distance
+// function must satisfy the triangle inequality.
+type BKTree[T any] struct {
+	dist func(a, b T) int
+	root *bkNode[T]
+	size int
+}
+
+type bkNode[T any] struct {
+	value    T
+	index    int
+	children map[int]*bkNode[T]
+}
+
+// NewBKTree creates an empty tree using dist as its metric.
+func NewBKTree[T any](dist func(a, b T) int) *BKTree[T] {
+	return &BKTree[T]{dist: dist}
+}
+
+// Hamming returns the number of differing bits between two hashes.
+func Hamming(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Len returns the number of entries in the tree.
+func (t *BKTree[T]) Len() int {
+	return t.size
+}
+
+// Insert adds a value with an associated index to the tree.
+func (t *BKTree[T]) Insert(value T, index int) {
+	t.size++
+	n := &bkNode[T]{value: value, index: index}
+	if t.root == nil {
+		t.root = n
+		return
+	}
+
+	cur := t.root
+	for {
+		d := t.dist(cur.value, value)
+		if cur.children == nil {
+			cur.children = make(map[int]*bkNode[T])
+		}
+		child, ok := cur.children[d]
+		if !ok {
+			cur.children[d] = n
+			return
+		}
+		cur = child
+	}
+}
+
+// Search returns every entry within radius of value.
+func (t *BKTree[T]) Search(value T, radius int) []Match {
+	if t.root == nil {
+		return nil
+	}
+
+	var matches []Match
+	stack := []*bkNode[T]{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := t.dist(n.value, value)
+		if d <= radius {
+			matches = append(matches, Match{Index: n.index, Distance: d})
+		}
+		// Triangle inequality: only children keyed within [d-radius, d+radius] can match.
+		for k, child := range n.children {
+			if k >= d-radius && k <= d+radius {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return matches
+}
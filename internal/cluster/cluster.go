@@ -0,0 +1,251 @@
+// Package cluster groups visually similar scan results using an indexed
+// nearest-neighbour search over perceptual, structural and text hashes.
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultThreshold is the maximum weighted-average Hamming distance between
+// an item and its cluster representative.
+const DefaultThreshold = 11
+
+// Signal identifies one 64-bit similarity hash of a page.
+type Signal int
+
+const (
+	SignalPHash Signal = iota // Perceptual hash of the screenshot
+	SignalDHash               // Difference hash of the screenshot
+	SignalAHash               // Average hash of the screenshot
+	SignalDOM                 // SimHash of the DOM tag structure
+	SignalText                // SimHash of the visible text
+	NumSignals
+)
+
+// Weights sets how much each signal contributes to the combined distance.
+// A zero weight disables the signal.
+type Weights [NumSignals]int
+
+// DefaultWeights favours the perceptual hash while letting structure and text
+// separate pages that merely share a layout or colour scheme.
+var DefaultWeights = Weights{
+	SignalPHash: 4,
+	SignalDHash: 2,
+	SignalAHash: 1,
+	SignalDOM:   2,
+	SignalText:  2,
+}
+
+// Total returns the sum of all weights.
+func (w Weights) Total() int {
+	total := 0
+	for _, v := range w {
+		total += v
+	}
+	return total
+}
+
+// SignalSet is a bit mask of signals.
+type SignalSet uint8
+
+// AllSignals has every signal set.
+const AllSignals SignalSet = 1<<NumSignals - 1
+
+// Has reports whether sig is in the set.
+func (s SignalSet) Has(sig Signal) bool {
+	return s&(1<<sig) != 0
+}
+
+// Item is a clusterable entry. ID must be unique (the result URL is used).
+// Present marks the signals that have a hash; the others are skipped when
+// the item is compared.
+type Item struct {
+	ID      string
+	Hashes  [NumSignals]uint64
+	Present SignalSet
+}
+
+// Set records the hash of one signal.
+func (it *Item) Set(sig Signal, hash uint64) {
+	it.Hashes[sig] = hash
+	it.Present |= 1 << sig
+}
+
+// Assignment describes the cluster an item was placed in.
+type Assignment struct {
+	Leader   string // ID of the cluster representative
+	Distance int    // Weighted-average Hamming distance to the representative
+}
+
+// Distance returns the weighted sum of per-signal Hamming distances. Being a
+// non-negative combination of metrics it is itself a metric, which keeps the
+// BK-tree search exact.
+func (w Weights) Distance(a, b [NumSignals]uint64) int {
+	d := 0
+	for s, weight := range w {
+		if weight > 0 {
+			d += weight * Hamming(a[s], b[s])
+		}
+	}
+	return d
+}
+
+// Only returns the weights of the signals in set, with the others disabled.
+func (w Weights) Only(set SignalSet) Weights {
+	var out Weights
+	for s, weight := range w {
+		if set.Has(Signal(s)) {
+			out[s] = weight
+		}
+	}
+	return out
+}
+
+// Compare returns the weighted distance between two items over the signals
+// both have, and the sum of those signals' weights. Dividing one by the
+// other gives a weighted average that is comparable whichever signals are
+// missing. A zero total means the items share no weighted signal.
+func (w Weights) Compare(a, b Item) (dist, total int) {
+	shared := w.Only(a.Present & b.Present)
+	return shared.Distance(a.Hashes, b.Hashes), shared.Total()
+}
+
+// Cluster assigns every item to a cluster. Items are processed in ID order and
+// each joins the nearest existing representative whose weighted-average
+// distance is within threshold (ties go to the lexically smallest
+// representative), otherwise it starts a new cluster. The result is therefore
+// independent of input order. Distances only use the signals both sides
+// have; items sharing no weighted signal are never grouped.
+func Cluster(items []Item, threshold int, weights Weights) map[string]Assignment {
+	if weights.Total() == 0 {
+		weights = Weights{SignalPHash: 1}
+	}
+
+	sorted := make([]Item, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	idx := &leaderIndex{
+		weights: weights,
+		masks:   make(map[SignalSet]bool),
+		trees:   make(map[SignalSet]*BKTree[[NumSignals]uint64]),
+	}
+	assignments := make(map[string]Assignment, len(items))
+
+	for _, it := range sorted {
+		if _, dup := assignments[it.ID]; dup {
+			continue
+		}
+
+		best := -1
+		bestDist, bestTotal := 0, 1
+		for shared := range idx.sharedSets(it.Present) {
+			total := weights.Only(shared).Total()
+			if total == 0 {
+				continue
+			}
+			// Comparing weighted sums against threshold*total is equivalent
+			// to comparing weighted averages against threshold, without
+			// rounding.
+			for _, m := range idx.tree(shared).Search(it.Hashes, threshold*total) {
+				leader := idx.leaders[m.Index]
+				if leader.Present&it.Present != shared {
+					continue // Compared over more signals in another tree
+				}
+				// Compare m.Distance/total with bestDist/bestTotal.
+				lhs, rhs := m.Distance*bestTotal, bestDist*total
+				if best == -1 || lhs < rhs || (lhs == rhs && leader.ID < idx.leaders[best].ID) {
+					best = m.Index
+					bestDist, bestTotal = m.Distance, total
+				}
+			}
+		}
+
+		if best == -1 {
+			idx.insert(it)
+			assignments[it.ID] = Assignment{Leader: it.ID}
+			continue
+		}
+		assignments[it.ID] = Assignment{
+			Leader:   idx.leaders[best].ID,
+			Distance: (bestDist + bestTotal/2) / bestTotal,
+		}
+	}
+
+	return assignments
+}
+
+// leaderIndex holds the cluster representatives. Skipping missing signals
+// makes the combined distance a different metric for each set of shared
+// signals, so there is one BK-tree per set, holding every leader that has
+// all of its signals. Trees are built on first use; in a typical scan every
+// page has every signal and there is only one.
+type leaderIndex struct {
+	weights Weights
+	leaders []Item
+	masks   map[SignalSet]bool // Distinct Present values of the leaders
+	trees   map[SignalSet]*BKTree[[NumSignals]uint64]
+}
+
+// sharedSets returns the signal sets an item with present shares with the
+// leaders.
+func (x *leaderIndex) sharedSets(present SignalSet) map[SignalSet]bool {
+	sets := make(map[SignalSet]bool, len(x.masks))
+	for mask := range x.masks {
+		sets[mask&present] = true
+	}
+	return sets
+}
+
+// tree returns the BK-tree over the signals in shared.
+func (x *leaderIndex) tree(shared SignalSet) *BKTree[[NumSignals]uint64] {
+	if t, ok := x.trees[shared]; ok {
+		return t
+	}
+	t := NewBKTree(x.weights.Only(shared).Distance)
+	for i, l := range x.leaders {
+		if l.Present&shared == shared {
+			t.Insert(l.Hashes, i)
+		}
+	}
+	x.trees[shared] = t
+	return t
+}
+
+// insert adds a leader to the index and to every tree it has the signals of.
+func (x *leaderIndex) insert(it Item) {
+	x.masks[it.Present] = true
+	for shared, t := range x.trees {
+		if it.Present&shared == shared {
+			t.Insert(it.Hashes, len(x.leaders))
+		}
+	}
+	x.leaders = append(x.leaders, it)
+}
+
+var signalNames = map[string]Signal{
+	"phash": SignalPHash,
+	"dhash": SignalDHash,
+	"ahash": SignalAHash,
+	"dom":   SignalDOM,
+	"text":  SignalText,
+}
+
+// WeightsFromMap builds Weights from a name->weight map (keys: phash, dhash,
+// ahash, dom, text). Signals not present keep their default weight.
+func WeightsFromMap(m map[string]int) (Weights, error) {
+	w := DefaultWeights
+	for name, v := range m {
+		s, ok := signalNames[strings.ToLower(name)]
+		if !ok {
+			return w, fmt.Errorf("unknown cluster signal %q", name)
+		}
+		if v < 0 {
+			return w, fmt.Errorf("negative weight for cluster signal %q", name)
+		}
+		w[s] = v
+	}
+	return w, nil
+}
@@ -0,0 +1,220 @@
+package cluster
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBKTreeSearchMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tree := NewBKTree(Hamming)
+	hashes := make([]uint64, 500)
+	for i := range hashes {
+		hashes[i] = rng.Uint64()
+		tree.Insert(hashes[i], i)
+	}
+
+	for q := 0; q < 50; q++ {
+		query := hashes[rng.Intn(len(hashes))] ^ (1 << uint(rng.Intn(64)))
+		for _, radius := range []int{0, 5, 20, 30} {
+			want := 0
+			for _, h := range hashes {
+				if Hamming(h, query) <= radius {
+					want++
+				}
+			}
+			if got := len(tree.Search(query, radius)); got != want {
+				t.Fatalf("Search(radius=%d) found %d; linear scan found %d", radius, got, want)
+			}
+		}
+	}
+}
+
+func pHashItem(id string, h uint64) Item {
+	it := Item{ID: id}
+	it.Set(SignalPHash, h)
+	return it
+}
+
+func TestClusterIsDeterministic(t *testing.T) {
+	base := uint64(0xF0F0F0F0F0F0F0F0)
+	items := []Item{
+		pHashItem("http://c.com", base^0x3),
+		pHashItem("http://a.com", base),
+		pHashItem("http://z.com", ^base),
+		pHashItem("http://b.com", base^0x1),
+	}
+	weights := Weights{SignalPHash: 1}
+
+	got := Cluster(items, DefaultThreshold, weights)
+
+	reversed := []Item{items[3], items[2], items[1], items[0]}
+	again := Cluster(reversed, DefaultThreshold, weights)
+
+	for id, a := range got {
+		if again[id] != a {
+			t.Errorf("assignment for %s depends on input order: %+v vs %+v", id, a, again[id])
+		}
+	}
+
+	if got["http://b.com"].Leader != "http://a.com" || got["http://b.com"].Distance != 1 {
+		t.Errorf("b.com assignment = %+v; want leader a.com at distance 1", got["http://b.com"])
+	}
+	if got["http://c.com"].Leader != "http://a.com" {
+		t.Errorf("c.com assignment = %+v; want leader a.com", got["http://c.com"])
+	}
+	if got["http://z.com"].Leader != "http://z.com" {
+		t.Errorf("z.com should start its own cluster, got %+v", got["http://z.com"])
+	}
+}
+
+// flip returns h with its lowest n bits inverted.
+func flip(h uint64, n int) uint64 {
+	return h ^ (1<<uint(n) - 1)
+}
+
+func TestClusterMultiSignal(t *testing.T) {
+	base := uint64(0xA5A5A5A5A5A5A5A5)
+	dom := uint64(0x0123456789ABCDEF)
+	text := uint64(0xFEDCBA9876543210)
+
+	page := Item{ID: "http://a.com", Hashes: [NumSignals]uint64{base, base, base, dom, text}, Present: AllSignals}
+
+	// Same white layout but a different page: images nearly identical,
+	// structure and text unrelated.
+	otherLogin := Item{ID: "http://b.com", Hashes: [NumSignals]uint64{flip(base, 1), base, base, flip(dom, 32), flip(text, 32)}, Present: AllSignals}
+
+	// Same page with a rotating banner: images drift, structure and text stable.
+	banner := Item{ID: "http://c.com", Hashes: [NumSignals]uint64{flip(base, 16), flip(base, 12), flip(base, 6), dom, flip(text, 2)}, Present: AllSignals}
+
+	got := Cluster([]Item{page, otherLogin, banner}, DefaultThreshold, DefaultWeights)
+
+	if got["http://b.com"].Leader == "http://a.com" {
+		t.Error("pages with different DOM and text were grouped together")
+	}
+	if got["http://c.com"].Leader != "http://a.com" {
+		t.Errorf("rotating banner page was split: %+v", got["http://c.com"])
+	}
+
+	pHashOnly := Cluster([]Item{page, otherLogin, banner}, DefaultThreshold, Weights{SignalPHash: 1})
+	if pHashOnly["http://c.com"].Leader == "http://a.com" || pHashOnly["http://b.com"].Leader != "http://a.com" {
+		t.Errorf("unexpected pHash-only baseline: %+v", pHashOnly)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	base := uint64(0xA5A5A5A5A5A5A5A5)
+	members := []Member{
+		{Item: pHashItem("http://a.com", flip(base, 4)), Title: "Sign in - Jenkins"},
+		{Item: pHashItem("http://b.com", base), Title: "Sign in - Jenkins"},
+		{Item: pHashItem("http://c.com", flip(base, 2)), Title: "Dashboard"},
+	}
+	weights := Weights{SignalPHash: 1}
+
+	sum := Summarize(members, weights, nil)
+	if sum.Representative != "http://c.com" {
+		t.Errorf("medoid = %s; want http://c.com", sum.Representative)
+	}
+	if sum.Label != "Sign in - Jenkins" {
+		t.Errorf("label = %q; want dominant title", sum.Label)
+	}
+	if sum.Distances["http://a.com"] != 2 || sum.Distances["http://c.com"] != 0 {
+		t.Errorf("unexpected distances: %v", sum.Distances)
+	}
+	if sum.Diversity != float64(2+2+0)/3 {
+		t.Errorf("diversity = %v", sum.Diversity)
+	}
+
+	lib := &LabelLibrary{Labels: []LabelEntry{{Name: "Jenkins login", Threshold: 3, hashes: []uint64{flip(base, 3)}}}}
+	if sum := Summarize(members, weights, lib); sum.Label != "Jenkins login" {
+		t.Errorf("library label = %q; want Jenkins login", sum.Label)
+	}
+}
+
+func TestClusterMissingSignals(t *testing.T) {
+	base := uint64(0xA5A5A5A5A5A5A5A5)
+	dom := uint64(0x0123456789ABCDEF)
+	text := uint64(0xFEDCBA9876543210)
+
+	page := Item{ID: "http://a.com", Hashes: [NumSignals]uint64{base, base, base, dom, text}, Present: AllSignals}
+
+	// The same page whose DOM and text hashes failed: the zero values must
+	// not count as 32 differing bits each.
+	noDOM := Item{ID: "http://b.com"}
+	noDOM.Set(SignalPHash, flip(base, 2))
+	noDOM.Set(SignalDHash, base)
+	noDOM.Set(SignalAHash, base)
+
+	// A different page of which only the pHash is known.
+	other := Item{ID: "http://c.com"}
+	other.Set(SignalPHash, ^base)
+
+	got := Cluster([]Item{page, noDOM, other}, DefaultThreshold, DefaultWeights)
+	if got["http://b.com"].Leader != "http://a.com" || got["http://b.com"].Distance != 1 {
+		t.Errorf("page without DOM and text hashes = %+v; want leader a.com at distance 1", got["http://b.com"])
+	}
+	if got["http://c.com"].Leader != "http://c.com" {
+		t.Errorf("unrelated page was grouped: %+v", got["http://c.com"])
+	}
+
+	textOnly := Item{ID: "http://d.com"}
+	textOnly.Set(SignalText, text)
+	if got := Cluster([]Item{noDOM, textOnly}, 64, DefaultWeights); got["http://d.com"].Leader != "http://d.com" {
+		t.Errorf("items without a shared signal were grouped: %+v", got["http://d.com"])
+	}
+
+	sum := Summarize([]Member{{Item: page}, {Item: noDOM}}, DefaultWeights, nil)
+	if d := sum.Distances["http://b.com"] + sum.Distances["http://a.com"]; d != 1 {
+		t.Errorf("distances = %v; want 1 between the two pages", sum.Distances)
+	}
+}
+
+// TestClusterMatchesLinearScan checks the per-signal-set index against a
+// pairwise comparison with every leader.
+func TestClusterMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	bases := []uint64{rng.Uint64(), rng.Uint64(), rng.Uint64()}
+	items := make([]Item, 300)
+	for i := range items {
+		it := Item{ID: fmt.Sprintf("http://%03d.com", i)}
+		base := bases[rng.Intn(len(bases))]
+		for s := Signal(0); s < NumSignals; s++ {
+			if s == SignalPHash || rng.Intn(4) > 0 {
+				it.Set(s, base^1<<uint(rng.Intn(64))^1<<uint(rng.Intn(64)))
+			}
+		}
+		items[i] = it
+	}
+
+	for _, threshold := range []int{0, 2, DefaultThreshold} {
+		got := Cluster(items, threshold, DefaultWeights)
+
+		want := make(map[string]Assignment)
+		var leaders []Item
+		for _, it := range items { // Already in ID order
+			best, bestDist, bestTotal := -1, 0, 1
+			for i, l := range leaders {
+				d, total := DefaultWeights.Compare(l, it)
+				if total == 0 || d > threshold*total {
+					continue
+				}
+				if best == -1 || d*bestTotal < bestDist*total {
+					best, bestDist, bestTotal = i, d, total
+				}
+			}
+			if best == -1 {
+				leaders = append(leaders, it)
+				want[it.ID] = Assignment{Leader: it.ID}
+				continue
+			}
+			want[it.ID] = Assignment{Leader: leaders[best].ID, Distance: (bestDist + bestTotal/2) / bestTotal}
+		}
+
+		for id, a := range want {
+			if got[id] != a {
+				t.Fatalf("threshold %d: %s = %+v; linear scan gives %+v", threshold, id, got[id], a)
+			}
+		}
+	}
+}
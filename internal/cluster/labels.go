@@ -0,0 +1,205 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLabelThreshold is the pHash distance used for library entries that
+// do not set their own threshold.
+const DefaultLabelThreshold = 8
+
+// LabelEntry maps one or more known pHashes to a human-readable name.
+type LabelEntry struct {
+	Name      string   `yaml:"name"`
+	PHashes   []string `yaml:"phashes"`
+	Threshold int      `yaml:"threshold"`
+
+	hashes []uint64
+}
+
+// LabelLibrary is a user-maintained list of known pages, e.g. "Jenkins login"
+// or "Tomcat default", used to name clusters consistently across scans.
+type LabelLibrary struct {
+	Labels []LabelEntry `yaml:"labels"`
+}
+
+// LoadLabelLibrary reads a label library from a YAML file.
+func LoadLabelLibrary(path string) (*LabelLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lib LabelLibrary
+	if err := yaml.Unmarshal(data, &lib); err != nil {
+		return nil, err
+	}
+
+	for i := range lib.Labels {
+		entry := &lib.Labels[i]
+		if entry.Threshold <= 0 {
+			entry.Threshold = DefaultLabelThreshold
+		}
+		for _, s := range entry.PHashes {
+			h, err := ParseHash(s)
+			if err != nil {
+				return nil, fmt.Errorf("label %q: invalid phash %q: %w", entry.Name, s, err)
+			}
+			entry.hashes = append(entry.hashes, h)
+		}
+	}
+	return &lib, nil
+}
+
+// Match returns the name of the closest library entry within its threshold.
+func (l *LabelLibrary) Match(phash uint64) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	best, bestDist := "", -1
+	for _, entry := range l.Labels {
+		for _, h := range entry.hashes {
+			d := Hamming(h, phash)
+			if d <= entry.Threshold && (bestDist == -1 || d < bestDist) {
+				best, bestDist = entry.Name, d
+			}
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// ParseHash decodes a "kind:hex" hash string (as produced by goimagehash and
+// screenshot.FormatSimHash) into its 64-bit value.
+func ParseHash(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty hash")
+	}
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		s = s[i+1:]
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// averageDistance returns the weighted-average Hamming distance of two items
+// over the signals both have, or the largest possible distance when they
+// share none.
+func averageDistance(weights Weights, a, b Item) float64 {
+	dist, total := weights.Compare(a, b)
+	if total == 0 {
+		return 64
+	}
+	return float64(dist) / float64(total)
+}
+
+// Member is a clustered item together with the page details used to describe
+// its cluster.
+type Member struct {
+	Item
+	Title        string
+	Technologies []string
+}
+
+// Summary describes one cluster.
+type Summary struct {
+	Representative string         // ID of the medoid member
+	Label          string         // Computed or library-supplied name
+	Distances      map[string]int // Weighted-average distance of each member to the medoid
+	Diversity      float64        // Mean distance of members to the medoid
+}
+
+// maxMedoidCandidates bounds the medoid search for very large clusters; the
+// candidates are the members closest to the cluster leader.
+const maxMedoidCandidates = 200
+
+// Summarize picks the medoid of members (the member with the smallest total
+// distance to all others), measures spread around it and labels the cluster.
+// Labels come from the library first, then the dominant title, then the
+// dominant technology.
+func Summarize(members []Member, weights Weights, lib *LabelLibrary) Summary {
+	if weights.Total() == 0 {
+		weights = Weights{SignalPHash: 1}
+	}
+	sorted := make([]Member, len(members))
+	copy(sorted, members)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	sum := Summary{Distances: make(map[string]int, len(sorted))}
+	if len(sorted) == 0 {
+		return sum
+	}
+
+	candidates := sorted
+	if len(candidates) > maxMedoidCandidates {
+		leader := sorted[0].Item
+		candidates = make([]Member, len(sorted))
+		copy(candidates, sorted)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return averageDistance(weights, candidates[i].Item, leader) < averageDistance(weights, candidates[j].Item, leader)
+		})
+		candidates = candidates[:maxMedoidCandidates]
+	}
+
+	medoid, bestCost := 0, -1.0
+	for ci, c := range candidates {
+		cost := 0.0
+		for _, m := range sorted {
+			cost += averageDistance(weights, c.Item, m.Item)
+		}
+		if bestCost < 0 || cost < bestCost {
+			medoid, bestCost = ci, cost
+		}
+	}
+	rep := candidates[medoid]
+	sum.Representative = rep.ID
+
+	spread := 0
+	for _, m := range sorted {
+		d := int(math.Round(averageDistance(weights, rep.Item, m.Item)))
+		sum.Distances[m.ID] = d
+		spread += d
+	}
+	sum.Diversity = float64(spread) / float64(len(sorted))
+
+	if name, ok := lib.Match(rep.Hashes[SignalPHash]); ok {
+		sum.Label = name
+		return sum
+	}
+
+	titles := make([]string, 0, len(sorted))
+	var techs []string
+	for _, m := range sorted {
+		titles = append(titles, strings.TrimSpace(m.Title))
+		techs = append(techs, m.Technologies...)
+	}
+	if title, ok := dominant(titles, len(sorted)); ok && title != "No Title" {
+		sum.Label = title
+	} else if tech, ok := dominant(techs, len(sorted)); ok {
+		sum.Label = tech
+	}
+	return sum
+}
+
+// dominant returns the most frequent non-empty value if it occurs in at least
+// half of n members. Ties go to the lexically smallest value.
+func dominant(values []string, n int) (string, bool) {
+	counts := make(map[string]int)
+	for _, v := range values {
+		if v != "" {
+			counts[v]++
+		}
+	}
+	best, bestCount := "", 0
+	for v, c := range counts {
+		if c > bestCount || (c == bestCount && v < best) {
+			best, bestCount = v, c
+		}
+	}
+	return best, bestCount > 0 && bestCount*2 >= n
+}
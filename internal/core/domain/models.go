@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,27 +13,177 @@ type Target struct {
 	Protocol string // http, https, etc.
 }
 
+// Technology describes a detected technology and where it was observed.
+type Technology struct {
+	Name    string
+	Version string
+	Source  string // header, body, rendered, waf, fingerprint, etc.
+}
+
+// String returns the display form of the technology, e.g. "React 18.2.0".
+func (t Technology) String() string {
+	if t.Version == "" {
+		return t.Name
+	}
+	return t.Name + " " + t.Version
+}
+
+// Form is an HTML form found on a page.
+type Form struct {
+	Action      string
+	Method      string
+	Inputs      []string // Names of input, select and textarea fields
+	HasPassword bool
+}
+
 // Metadata contains scan-time information about a host.
 type Metadata struct {
-	Title      string
+	Title        string
+	StatusCode   int
+	Technology   []string
+	Technologies []Technology
+	Headers      map[string]string
+	Cookies      []string // Raw Set-Cookie header values, one per cookie
+	ContentLen   int64
+	Timestamp    time.Time
+	Redirects    []string
+
+	// Parsed from the HTML body
+	Description string
+	Generator   string
+	Canonical   string
+	Forms       []Form
+	Scripts     []string // Absolute script src URLs
+	Links       []string // Absolute anchor href URLs
+	Iframes     []string // Absolute iframe/frame src URLs
+
+	Body          string         // Response body decoded to UTF-8, cleared once the analyzers ran
+	ScriptSources []ScriptSource // Fetched script files, cleared once the analyzers ran
+}
+
+// ScriptSource is the content of a script referenced by a page.
+type ScriptSource struct {
+	URL  string
+	Body string
+}
+
+// AddTechnology merges t into the structured technology list and keeps the
+// flat Technology list in sync. A technology already present by name only
+// gains a version if it was previously unknown.
+func (m *Metadata) AddTechnology(t Technology) {
+	if t.Name == "" {
+		return
+	}
+	for i, existing := range m.Technologies {
+		if !strings.EqualFold(existing.Name, t.Name) {
+			continue
+		}
+		if existing.Version == "" && t.Version != "" {
+			old := existing.String()
+			m.Technologies[i].Version = t.Version
+			for j, flat := range m.Technology {
+				if flat == old {
+					m.Technology[j] = m.Technologies[i].String()
+				}
+			}
+		}
+		return
+	}
+
+	m.Technologies = append(m.Technologies, t)
+	for _, flat := range m.Technology {
+		if strings.EqualFold(flat, t.Name) || strings.EqualFold(flat, t.String()) {
+			return
+		}
+	}
+	m.Technology = append(m.Technology, t.String())
+}
+
+// PageHashes holds the similarity hashes used for clustering alongside the pHash.
+type PageHashes struct {
+	DHash    string // Difference hash of the screenshot
+	AHash    string // Average hash of the screenshot
+	DOMHash  string // SimHash of the DOM tag structure
+	TextHash string // SimHash of the visible text
+}
+
+// Severity ranks how serious a finding is.
+type Severity string
+
+// Finding severities, from least to most serious.
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Finding is a structured observation produced by an analyzer.
+type Finding struct {
+	Analyzer   string // Name of the analyzer that produced the finding
+	ID         string // Stable rule identifier, e.g. "missing-hsts"
+	Title      string // Short human-readable description
+	Severity   Severity
+	Confidence string // low, medium or high, for heuristic findings
+	Evidence   string // Offending value or excerpt, already redacted where needed
+
+	// Where the evidence was found, for findings that come from a document
+	Location string // URL of the page or script
+	Line     int    // 1-based line number
+	Offset   int    // Byte offset in the document
+}
+
+// Endpoint is a URL or route referenced by a page or its scripts.
+type Endpoint struct {
+	URL    string // Absolute URL, resolved against the page
+	Kind   string // url, path, api, graphql or sourcemap
+	Source string // URL of the page or script it was found in
+}
+
+// PathHit is a probed path that returned something other than a (soft) 404.
+type PathHit struct {
+	URL        string
+	Path       string
 	StatusCode int
-	Technology []string
-	Headers    map[string]string
-	ContentLen int64
-	Timestamp  time.Time
-	Redirects  []string
+	Length     int64
+	Title      string
+	Screenshot string
+	Thumbnail  string
+}
+
+// RenderResult holds everything captured while rendering a target in a browser.
+type RenderResult struct {
+	Path         string
+	Thumbnail    string
+	PHash        string
+	Hashes       PageHashes
+	Technologies []Technology
+	ConsentRule  string // Consent/overlay rule that fired before the capture
 }
 
 // ScanResult aggregates all information gathered for a target.
 type ScanResult struct {
-	Target     Target
-	Metadata   Metadata
-	PHash      string
-	Screenshot string // Path or identifier for the screenshot
-	PHashScore uint64
-	GroupID    string // Cluster/Group ID
-	IsAlive    bool
-	Error      string
+	Target        Target
+	Metadata      Metadata
+	PHash         string
+	Hashes        PageHashes
+	Screenshot    string // Path or identifier for the screenshot
+	Thumbnail     string // Path to a downscaled copy of the screenshot, if generated
+	PHashScore    uint64 // Hamming distance to the cluster representative
+	GroupID       string // Cluster/Group ID (URL of the cluster representative)
+	ClusterLabel  string // Human-readable cluster name, if one could be derived
+	ConsentRule   string // Consent/overlay rule that fired before the capture
+	PageClass     string // Page type such as login or admin-panel, if recognised
+	Findings      []Finding
+	SecurityScore int    // Header/cookie posture score from 0 to 100
+	SecurityGrade string // Letter grade derived from SecurityScore
+	Endpoints     []Endpoint
+	Paths         []PathHit // Hits from the common path probe
+	CNAMEs        []string  // CNAME chain of the hostname, if resolved
+	Discovered    []Target  // In-scope targets suggested by analyzers for a follow-up round
+	IsAlive       bool
+	Error         string
 }
 
 // Config represents the application configuration.
@@ -45,4 +196,33 @@ type Config struct {
 	OutputPath  string
 	FullPage    bool
 	UserAgent   string
+
+	// MaxDiscovered caps how many targets found by analyzers are added to
+	// the scan in a follow-up round. Zero disables following.
+	MaxDiscovered int
+}
+
+// EventType names a step in the life of a scan.
+type EventType string
+
+// Scan events, in the order a scan emits them.
+const (
+	EventTargetsQueued  EventType = "targets_queued"  // Total targets were added to the scan
+	EventTargetStarted  EventType = "target_started"  // A worker picked up Target
+	EventTargetProbed   EventType = "target_probed"   // Err is set if every probe attempt failed
+	EventTargetRendered EventType = "target_rendered" // Err is set if the capture failed
+	EventTargetAnalyzed EventType = "target_analyzed" // Analyzers ran on Result
+	EventTargetFailed   EventType = "target_failed"   // Result.Error is set; Err holds the same message
+	EventTargetFinished EventType = "target_finished" // Result holds the outcome, successful or not
+	EventScanFinished   EventType = "scan_finished"   // Err is set if the scan failed or was cancelled
+)
+
+// Event reports scan progress to observers of a ScannerService.
+type Event struct {
+	Type   EventType
+	Time   time.Time
+	Target Target // As queued, in every event for it; Result.Target holds the URL it resolved to
+	Result *ScanResult
+	Total  int
+	Err    error
 }
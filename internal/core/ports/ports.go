@@ -13,7 +13,7 @@ type Prober interface {
 
 // Renderer defines the interface for capturing visual snapshots of targets.
 type Renderer interface {
-	Render(ctx context.Context, target domain.Target) (string, string, string, error)
+	Render(ctx context.Context, target domain.Target) (*domain.RenderResult, error)
 	Close() error
 }
 
@@ -23,11 +23,31 @@ type Analyzer interface {
 	Name() string
 }
 
+// Clusterer defines the interface for grouping visually similar results.
+// Implementations set GroupID and PHashScore on each result in place.
+type Clusterer interface {
+	Cluster(ctx context.Context, results []domain.ScanResult) error
+}
+
 // Reporter defines the interface for generating scan reports.
 type Reporter interface {
 	Report(ctx context.Context, results []domain.ScanResult) error
 }
 
+// EventSubscriber receives scan progress events, such as each finished
+// result, while a scan runs.
+type EventSubscriber interface {
+	HandleEvent(ctx context.Context, event domain.Event)
+}
+
+// EventFunc adapts an ordinary function to an EventSubscriber.
+type EventFunc func(ctx context.Context, event domain.Event)
+
+// HandleEvent calls f(ctx, event).
+func (f EventFunc) HandleEvent(ctx context.Context, event domain.Event) {
+	f(ctx, event)
+}
+
 // Storage defines the interface for persisting scan data.
 type Storage interface {
 	Save(ctx context.Context, result domain.ScanResult) error
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -16,9 +17,29 @@ type ScannerService struct {
 	prober    ports.Prober
 	renderer  ports.Renderer
 	analyzers []ports.Analyzer
+	clusterer ports.Clusterer
 	reporter  ports.Reporter
 	config    domain.Config
 	logger    *slog.Logger
+
+	handlers []func(domain.Event)
+	subs     []*subscription
+}
+
+// eventQueueSize is how many events may wait for one subscriber before the
+// scan waits for it to catch up.
+const eventQueueSize = 256
+
+// retryDelay is the base wait between probe and render attempts.
+var retryDelay = time.Second
+
+// subscription delivers events to one subscriber through its own queue, so
+// a slow subscriber never delays the others.
+type subscription struct {
+	sub   ports.EventSubscriber
+	types map[domain.EventType]bool // Nil means every type
+	queue chan domain.Event
+	done  chan struct{}
 }
 
 // NewScannerService creates a new ScannerService.
@@ -26,6 +47,7 @@ func NewScannerService(
 	prober ports.Prober,
 	renderer ports.Renderer,
 	analyzers []ports.Analyzer,
+	clusterer ports.Clusterer,
 	reporter ports.Reporter,
 	config domain.Config,
 	logger *slog.Logger,
@@ -34,14 +56,84 @@ func NewScannerService(
 		prober:    prober,
 		renderer:  renderer,
 		analyzers: analyzers,
+		clusterer: clusterer,
 		reporter:  reporter,
 		config:    config,
 		logger:    logger,
 	}
 }
 
-// Scan performs a scan on a list of targets.
-func (s *ScannerService) Scan(ctx context.Context, targets []domain.Target) error {
+// OnEvent registers fn to be called synchronously for every scan event.
+// It runs on the worker goroutines, so it must be safe for concurrent use
+// and return quickly; use Subscribe for anything slower. Register handlers
+// before calling Scan.
+func (s *ScannerService) OnEvent(fn func(domain.Event)) {
+	s.handlers = append(s.handlers, fn)
+}
+
+// Subscribe registers sub for events of the given types, or of every type
+// when none are given. Each subscriber receives its events in order on its
+// own goroutine, so it may do slow work such as network calls; Scan returns
+// only once every subscriber has handled its events. The context passed to
+// sub outlives a cancelled scan so that final events can still be handled.
+// Register subscribers before calling Scan.
+func (s *ScannerService) Subscribe(sub ports.EventSubscriber, types ...domain.EventType) {
+	subscription := &subscription{sub: sub}
+	if len(types) > 0 {
+		subscription.types = make(map[domain.EventType]bool, len(types))
+		for _, t := range types {
+			subscription.types[t] = true
+		}
+	}
+	s.subs = append(s.subs, subscription)
+}
+
+func (s *ScannerService) emit(e domain.Event) {
+	e.Time = time.Now()
+	for _, fn := range s.handlers {
+		fn(e)
+	}
+	for _, sub := range s.subs {
+		if sub.queue != nil && (sub.types == nil || sub.types[e.Type]) {
+			sub.queue <- e
+		}
+	}
+}
+
+// startDelivery starts a delivery goroutine for each subscriber.
+func (s *ScannerService) startDelivery(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	for _, sub := range s.subs {
+		sub.queue = make(chan domain.Event, eventQueueSize)
+		sub.done = make(chan struct{})
+		go func(sub *subscription, queue <-chan domain.Event) {
+			defer close(sub.done)
+			for e := range queue {
+				sub.sub.HandleEvent(ctx, e)
+			}
+		}(sub, sub.queue)
+	}
+}
+
+// stopDelivery waits for every subscriber to drain its queue.
+func (s *ScannerService) stopDelivery() {
+	for _, sub := range s.subs {
+		close(sub.queue)
+		<-sub.done
+		sub.queue = nil
+	}
+}
+
+// Scan performs a scan on a list of targets. When ctx is cancelled, targets
+// not yet started are skipped and the results gathered so far are still
+// reported before ctx's error is returned.
+func (s *ScannerService) Scan(ctx context.Context, targets []domain.Target) (err error) {
+	s.startDelivery(ctx)
+	defer func() {
+		s.emit(domain.Event{Type: domain.EventScanFinished, Err: err})
+		s.stopDelivery()
+	}()
+
 	// Deduplicate targets by URL
 	uniqueTargets := make(map[string]domain.Target)
 	for _, t := range targets {
@@ -55,9 +147,44 @@ func (s *ScannerService) Scan(ctx context.Context, targets []domain.Target) erro
 
 	s.logger.Info("Starting advanced scan", "targets", len(targets), "concurrency", s.config.Concurrency)
 
+	scanResults := s.scanBatch(ctx, targets)
+
+	// Follow-up round for in-scope targets discovered by analyzers
+	if followUp := s.discovered(targets, scanResults); len(followUp) > 0 && ctx.Err() == nil {
+		s.logger.Info("Scanning discovered targets", "targets", len(followUp))
+		scanResults = append(scanResults, s.scanBatch(ctx, followUp)...)
+	}
+
+	// A cancelled scan still reports the targets it finished.
+	cancelled := ctx.Err()
+	ctx = context.WithoutCancel(ctx)
+
+	// Cluster results by visual similarity before reporting
+	if s.clusterer != nil {
+		if err := s.clusterer.Cluster(ctx, scanResults); err != nil {
+			s.logger.Warn("Clustering failed", "error", err)
+		}
+	}
+
+	s.logger.Info("Scan completed, generating reports...")
+	if s.reporter != nil {
+		if err := s.reporter.Report(ctx, scanResults); err != nil {
+			return fmt.Errorf("reporting failed: %w", err)
+		}
+	}
+
+	if cancelled != nil {
+		return fmt.Errorf("scan cancelled after %d results: %w", len(scanResults), cancelled)
+	}
+	return nil
+}
+
+// scanBatch processes targets concurrently and returns their results.
+func (s *ScannerService) scanBatch(ctx context.Context, targets []domain.Target) []domain.ScanResult {
 	var wg sync.WaitGroup
 	results := make(chan domain.ScanResult, len(targets))
 	workers := make(chan struct{}, s.config.Concurrency)
+	s.emit(domain.Event{Type: domain.EventTargetsQueued, Total: len(targets)})
 
 	for _, t := range targets {
 		wg.Add(1)
@@ -65,8 +192,17 @@ func (s *ScannerService) Scan(ctx context.Context, targets []domain.Target) erro
 			defer wg.Done()
 			workers <- struct{}{}
 			defer func() { <-workers }()
+			if ctx.Err() != nil {
+				return
+			}
 
-			results <- s.processTarget(ctx, target)
+			s.emit(domain.Event{Type: domain.EventTargetStarted, Target: target})
+			res := s.processTarget(ctx, target)
+			if res.Error != "" {
+				s.emit(domain.Event{Type: domain.EventTargetFailed, Target: target, Result: &res, Err: errors.New(res.Error)})
+			}
+			s.emit(domain.Event{Type: domain.EventTargetFinished, Target: target, Result: &res})
+			results <- res
 		}(t)
 	}
 
@@ -83,18 +219,37 @@ func (s *ScannerService) Scan(ctx context.Context, targets []domain.Target) erro
 			s.logger.Warn("Scan result with error", "url", res.Target.URL, "error", res.Error)
 		}
 	}
+	return scanResults
+}
 
-	// Cluster results for reporting (simple grouping by domain or visual similarity)
-	// This is now handled in the reporter adapter for v1 compatibility
+// discovered returns up to config.MaxDiscovered targets suggested by
+// analyzers that were not already scanned.
+func (s *ScannerService) discovered(scanned []domain.Target, results []domain.ScanResult) []domain.Target {
+	if s.config.MaxDiscovered <= 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, t := range scanned {
+		seen[t.URL] = true
+	}
+	for _, r := range results {
+		seen[r.Target.URL] = true
+	}
 
-	s.logger.Info("Scan completed, generating reports...")
-	if s.reporter != nil {
-		if err := s.reporter.Report(ctx, scanResults); err != nil {
-			return fmt.Errorf("reporting failed: %w", err)
+	var followUp []domain.Target
+	for _, r := range results {
+		for _, t := range r.Discovered {
+			if seen[t.URL] {
+				continue
+			}
+			seen[t.URL] = true
+			followUp = append(followUp, t)
+			if len(followUp) >= s.config.MaxDiscovered {
+				return followUp
+			}
 		}
 	}
-
-	return nil
+	return followUp
 }
 
 func (s *ScannerService) processTarget(ctx context.Context, t domain.Target) domain.ScanResult {
@@ -114,13 +269,17 @@ func (s *ScannerService) processTarget(ctx context.Context, t domain.Target) dom
 		}
 		if i < 2 {
 			s.logger.Warn("Probe failed, retrying...", "url", t.URL, "attempt", i+1, "error", err)
-			time.Sleep(time.Duration(i+1) * time.Second)
+			sleep(ctx, time.Duration(i+1)*retryDelay)
 		}
 	}
 
+	s.emit(domain.Event{Type: domain.EventTargetProbed, Target: t, Err: err})
 	if err != nil {
 		result.Error = fmt.Sprintf("probe failed after retries: %v", err)
 		result.IsAlive = false
+		// Analyzers still run: some, like takeover detection, matter most
+		// for hosts that no longer respond.
+		s.analyze(ctx, t, &result)
 		return result
 	}
 	result.Metadata = *metadata
@@ -129,34 +288,59 @@ func (s *ScannerService) processTarget(ctx context.Context, t domain.Target) dom
 
 	// 2. Render (Screenshot) with optional retry
 	if s.renderer != nil {
-		var path, phash, framework string
+		var rendered *domain.RenderResult
 		for i := 0; i <= 1; i++ { // 2 attempts total for rendering
-			path, phash, framework, err = s.renderer.Render(ctx, result.Target)
+			rendered, err = s.renderer.Render(ctx, result.Target)
 			if err == nil {
 				break
 			}
 			if i < 1 {
 				s.logger.Warn("Render failed, retrying...", "url", result.Target.URL, "attempt", i+1, "error", err)
-				time.Sleep(2 * time.Second)
+				sleep(ctx, 2*retryDelay)
 			}
 		}
 		if err == nil {
-			result.Screenshot = path
-			result.PHash = phash
-			if framework != "Static" && framework != "" {
-				result.Metadata.Technology = append(result.Metadata.Technology, framework)
+			result.Screenshot = rendered.Path
+			result.Thumbnail = rendered.Thumbnail
+			result.PHash = rendered.PHash
+			result.Hashes = rendered.Hashes
+			result.ConsentRule = rendered.ConsentRule
+			for _, tech := range rendered.Technologies {
+				result.Metadata.AddTechnology(tech)
 			}
 		} else {
 			s.logger.Warn("Render failed after retries", "url", result.Target.URL, "error", err)
 		}
+		s.emit(domain.Event{Type: domain.EventTargetRendered, Target: t, Err: err})
 	}
 
 	// 3. Analyze
+	s.analyze(ctx, t, &result)
+
+	return result
+}
+
+// analyze runs every analyzer on the result for t. Failures are logged and
+// skipped.
+func (s *ScannerService) analyze(ctx context.Context, t domain.Target, result *domain.ScanResult) {
 	for _, analyzer := range s.analyzers {
-		if err := analyzer.Analyze(ctx, &result); err != nil {
-			s.logger.Warn("Analysis failed", "analyzer", analyzer.Name(), "url", t.URL, "error", err)
+		if err := analyzer.Analyze(ctx, result); err != nil {
+			s.logger.Warn("Analysis failed", "analyzer", analyzer.Name(), "url", result.Target.URL, "error", err)
 		}
 	}
+	// The page and script bodies are only input for the analyzers; results
+	// are kept for the whole scan, so holding on to them would not scale.
+	result.Metadata.Body = ""
+	result.Metadata.ScriptSources = nil
+	s.emit(domain.Event{Type: domain.EventTargetAnalyzed, Target: t, Result: result})
+}
 
-	return result
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
 }
@@ -2,13 +2,18 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/core/ports"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockProber is a mock implementation of ports.Prober
@@ -29,9 +34,12 @@ type MockRenderer struct {
 	mock.Mock
 }
 
-func (m *MockRenderer) Render(ctx context.Context, target domain.Target) (string, string, string, error) {
+func (m *MockRenderer) Render(ctx context.Context, target domain.Target) (*domain.RenderResult, error) {
 	args := m.Called(ctx, target)
-	return args.String(0), args.String(1), args.String(2), args.Error(3)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RenderResult), args.Error(1)
 }
 
 func (m *MockRenderer) Close() error {
@@ -63,10 +71,14 @@ func TestScannerService_Scan(t *testing.T) {
 
 	metadata := &domain.Metadata{Title: "Example"}
 	mockProber.On("Probe", mock.Anything, targets[0]).Return(metadata, "http://example.com", nil)
-	mockRenderer.On("Render", mock.Anything, targets[0]).Return("path/to/img", "hash", "React", nil)
+	mockRenderer.On("Render", mock.Anything, targets[0]).Return(&domain.RenderResult{
+		Path:         "path/to/img",
+		PHash:        "hash",
+		Technologies: []domain.Technology{{Name: "React", Version: "18.2.0", Source: "rendered"}},
+	}, nil)
 	mockReporter.On("Report", mock.Anything, mock.Anything).Return(nil)
 
-	svc := NewScannerService(mockProber, mockRenderer, nil, mockReporter, domain.Config{Concurrency: 1}, logger)
+	svc := NewScannerService(mockProber, mockRenderer, nil, nil, mockReporter, domain.Config{Concurrency: 1}, logger)
 
 	err := svc.Scan(ctx, targets)
 
@@ -75,3 +87,189 @@ func TestScannerService_Scan(t *testing.T) {
 	mockRenderer.AssertExpectations(t)
 	mockReporter.AssertExpectations(t)
 }
+
+// discoverAnalyzer suggests a fixed follow-up target for the root page.
+type discoverAnalyzer struct{}
+
+func (discoverAnalyzer) Name() string { return "discover" }
+
+func (discoverAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if result.Target.URL == "http://example.com" {
+		result.Discovered = []domain.Target{{URL: "http://example.com/admin"}, {URL: "http://example.com"}}
+	}
+	return nil
+}
+
+func TestScannerService_FollowsDiscoveredTargets(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	mockProber := new(MockProber)
+	mockReporter := new(MockReporter)
+
+	root := domain.Target{URL: "http://example.com"}
+	admin := domain.Target{URL: "http://example.com/admin"}
+	mockProber.On("Probe", mock.Anything, root).Return(&domain.Metadata{Title: "Home"}, root.URL, nil).Once()
+	mockProber.On("Probe", mock.Anything, admin).Return(&domain.Metadata{Title: "Admin"}, admin.URL, nil).Once()
+	mockReporter.On("Report", mock.Anything, mock.MatchedBy(func(results []domain.ScanResult) bool {
+		return len(results) == 2
+	})).Return(nil)
+
+	svc := NewScannerService(mockProber, nil, []ports.Analyzer{discoverAnalyzer{}}, nil, mockReporter,
+		domain.Config{Concurrency: 1, MaxDiscovered: 10}, logger)
+
+	assert.NoError(t, svc.Scan(ctx, []domain.Target{root}))
+	mockProber.AssertExpectations(t)
+	mockReporter.AssertExpectations(t)
+}
+
+func TestScannerService_EventsAndCancel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := domain.Target{URL: "http://a.example.com"}
+	second := domain.Target{URL: "http://b.example.com"}
+	mockProber := new(MockProber)
+	mockProber.On("Probe", mock.Anything, mock.Anything).Return(&domain.Metadata{Title: "A"}, first.URL, nil).Once()
+	mockReporter := new(MockReporter)
+	mockReporter.On("Report", mock.Anything, mock.MatchedBy(func(results []domain.ScanResult) bool {
+		return len(results) == 1
+	})).Return(nil)
+
+	svc := NewScannerService(mockProber, nil, nil, nil, mockReporter, domain.Config{Concurrency: 1}, logger)
+	var events []domain.Event
+	svc.OnEvent(func(e domain.Event) {
+		events = append(events, e)
+		if e.Type == domain.EventTargetFinished {
+			cancel() // Stop before the second target starts
+		}
+	})
+
+	err := svc.Scan(ctx, []domain.Target{first, second})
+	assert.ErrorIs(t, err, context.Canceled)
+	mockReporter.AssertExpectations(t)
+
+	var types []domain.EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []domain.EventType{
+		domain.EventTargetsQueued, domain.EventTargetStarted, domain.EventTargetProbed,
+		domain.EventTargetAnalyzed, domain.EventTargetFinished, domain.EventScanFinished,
+	}, types)
+	assert.Equal(t, 2, events[0].Total)
+	assert.NoError(t, events[2].Err)
+	assert.Equal(t, "A", events[4].Result.Metadata.Title)
+	assert.ErrorIs(t, events[5].Err, context.Canceled)
+}
+
+func TestScannerService_Subscribe(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	up := domain.Target{URL: "http://up.example.com"}
+	down := domain.Target{URL: "http://down.example.com"}
+	mockProber := new(MockProber)
+	mockProber.On("Probe", mock.Anything, up).Return(&domain.Metadata{Title: "Up"}, up.URL, nil)
+	mockProber.On("Probe", mock.Anything, down).Return(nil, "", errors.New("connection refused"))
+
+	svc := NewScannerService(mockProber, nil, nil, nil, nil, domain.Config{Concurrency: 2}, logger)
+	var mu sync.Mutex
+	var finished, failed []string
+	var all int
+	// A slow subscriber still sees every event before Scan returns.
+	svc.Subscribe(ports.EventFunc(func(ctx context.Context, e domain.Event) {
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Type {
+		case domain.EventTargetFinished:
+			finished = append(finished, e.Result.Target.URL)
+		case domain.EventTargetFailed:
+			failed = append(failed, e.Target.URL)
+			assert.EqualError(t, e.Err, e.Result.Error)
+		}
+	}), domain.EventTargetFinished, domain.EventTargetFailed)
+	svc.Subscribe(ports.EventFunc(func(ctx context.Context, e domain.Event) { all++ }))
+
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = 0
+	assert.NoError(t, svc.Scan(context.Background(), []domain.Target{up, down}))
+	assert.ElementsMatch(t, []string{up.URL, down.URL}, finished)
+	assert.Equal(t, []string{down.URL}, failed)
+	// Queued, 2x (started, probed, analyzed, finished), failed and scan finished.
+	assert.Equal(t, 1+2*4+1+1, all)
+}
+
+// scriptAnalyzer stands in for the script collector and records how many
+// scripts the next analyzer sees.
+type scriptAnalyzer struct{ seen *int }
+
+func (scriptAnalyzer) Name() string { return "scripts" }
+
+func (a scriptAnalyzer) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if result.Metadata.ScriptSources == nil {
+		result.Metadata.ScriptSources = []domain.ScriptSource{{URL: "http://example.com/app.js", Body: "var x = 1"}}
+		return nil
+	}
+	*a.seen = len(result.Metadata.ScriptSources)
+	if result.Metadata.Body == "" {
+		*a.seen = -1
+	}
+	return nil
+}
+
+func TestScannerService_ReleasesAnalyzerInputs(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	target := domain.Target{URL: "http://example.com"}
+	mockProber := new(MockProber)
+	mockProber.On("Probe", mock.Anything, target).Return(&domain.Metadata{Title: "A", Body: "<html></html>"}, target.URL, nil)
+	mockReporter := new(MockReporter)
+	mockReporter.On("Report", mock.Anything, mock.MatchedBy(func(results []domain.ScanResult) bool {
+		return len(results) == 1 && results[0].Metadata.ScriptSources == nil && results[0].Metadata.Body == ""
+	})).Return(nil)
+
+	seen := 0
+	collector := scriptAnalyzer{seen: &seen}
+	svc := NewScannerService(mockProber, nil, []ports.Analyzer{collector, collector}, nil, mockReporter, domain.Config{Concurrency: 1}, logger)
+	var finished *domain.ScanResult
+	svc.OnEvent(func(e domain.Event) {
+		if e.Type == domain.EventTargetFinished {
+			finished = e.Result
+		}
+	})
+
+	assert.NoError(t, svc.Scan(context.Background(), []domain.Target{target}))
+	assert.Equal(t, 1, seen, "later analyzers still get the body and scripts")
+	assert.Nil(t, finished.Metadata.ScriptSources)
+	assert.Empty(t, finished.Metadata.Body)
+	mockReporter.AssertExpectations(t)
+}
+
+// TestScannerService_EventTargets checks that every event about a target
+// carries it as queued, even when the probe resolves it to another URL.
+func TestScannerService_EventTargets(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	target := domain.Target{URL: "example.com"}
+	resolved := domain.Target{URL: "https://example.com/"}
+	mockProber := new(MockProber)
+	mockProber.On("Probe", mock.Anything, target).Return(&domain.Metadata{Title: "Example"}, resolved.URL, nil)
+	mockRenderer := new(MockRenderer)
+	mockRenderer.On("Render", mock.Anything, resolved).Return(&domain.RenderResult{Path: "path/to/img"}, nil)
+
+	svc := NewScannerService(mockProber, mockRenderer, nil, nil, nil, domain.Config{Concurrency: 1}, logger)
+	var events []domain.Event
+	svc.OnEvent(func(e domain.Event) {
+		if e.Type != domain.EventTargetsQueued && e.Type != domain.EventScanFinished {
+			events = append(events, e)
+		}
+	})
+	require.NoError(t, svc.Scan(context.Background(), []domain.Target{target}))
+
+	require.Len(t, events, 5, "started, probed, rendered, analyzed and finished")
+	for _, e := range events {
+		assert.Equal(t, target, e.Target, e.Type)
+		if e.Result != nil {
+			assert.Equal(t, resolved, e.Result.Target, e.Type)
+		}
+	}
+}
@@ -2,30 +2,38 @@ package adapters
 
 import (
 	"context"
+	"strings"
 
 	"github.com/ismailtsdln/netvista/internal/core/domain"
 	"github.com/ismailtsdln/netvista/internal/plugins"
 	"github.com/ismailtsdln/netvista/pkg/models"
 )
 
-// WafAnalyzerAdapter wraps the WafPlugin.
+// wafPrefix marks the technologies WafPlugin adds for a detected WAF.
+const wafPrefix = "WAF:"
+
+// WafAnalyzerAdapter wraps the WafPlugin, or another legacy plugin that adds
+// technologies.
 type WafAnalyzerAdapter struct {
-	p *plugins.WafPlugin
+	p plugins.Plugin
 }
 
 // NewWafAnalyzerAdapter creates a new Waf analyzer adapter.
-func NewWafAnalyzerAdapter(p *plugins.WafPlugin) *WafAnalyzerAdapter {
+func NewWafAnalyzerAdapter(p plugins.Plugin) *WafAnalyzerAdapter {
 	return &WafAnalyzerAdapter{p: p}
 }
 
 // Analyze performs WAF detection on the result.
 func (a *WafAnalyzerAdapter) Analyze(ctx context.Context, result *domain.ScanResult) error {
+	if !result.IsAlive {
+		return nil
+	}
 	// Map to legacy model for plugin compatibility
 	legacyTarget := &models.Target{
 		URL: result.Target.URL,
 		Metadata: models.ResponseMetadata{
 			Headers:    result.Metadata.Headers,
-			Technology: result.Metadata.Technology,
+			Technology: append([]string(nil), result.Metadata.Technology...),
 		},
 	}
 
@@ -33,8 +41,16 @@ func (a *WafAnalyzerAdapter) Analyze(ctx context.Context, result *domain.ScanRes
 		return err
 	}
 
-	// Map back
-	result.Metadata.Technology = legacyTarget.Metadata.Technology
+	// Map back, recording new entries in the structured list as well. Only
+	// WAF detections come from the WAF signatures; anything else the plugin
+	// adds is a signature or fingerprint match.
+	for _, tech := range legacyTarget.Metadata.Technology[len(result.Metadata.Technology):] {
+		source := "fingerprint"
+		if strings.HasPrefix(tech, wafPrefix) {
+			source = "waf"
+		}
+		result.Metadata.AddTechnology(domain.Technology{Name: tech, Source: source})
+	}
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/plugins"
+	"github.com/ismailtsdln/netvista/pkg/signatures"
+)
+
+func TestWafAnalyzerAdapter_Sources(t *testing.T) {
+	headers := map[string]string{"Server": "cloudflare", "X-Powered-By": "Express"}
+	sources := func(p plugins.Plugin) map[string]string {
+		res := &domain.ScanResult{IsAlive: true, Metadata: domain.Metadata{Headers: headers}}
+		require.NoError(t, NewWafAnalyzerAdapter(p).Analyze(context.Background(), res))
+		out := make(map[string]string)
+		for _, tech := range res.Metadata.Technologies {
+			out[tech.Name] = tech.Source
+		}
+		return out
+	}
+
+	waf := sources(plugins.NewWafPlugin([]signatures.WafSig{{Name: "Cloudflare", Header: map[string]string{"Server": "cloudflare"}}}))
+	assert.Equal(t, map[string]string{"WAF:Cloudflare": "waf"}, waf)
+
+	fingerprints := sources(plugins.NewFingerprintPlugin(nil))
+	assert.Equal(t, map[string]string{"cloudflare": "fingerprint", "Express": "fingerprint"}, fingerprints)
+}
@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/cluster"
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// ClusterAdapter groups results by combined visual, structural and text
+// similarity using the cluster package.
+type ClusterAdapter struct {
+	threshold int
+	weights   cluster.Weights
+	labels    *cluster.LabelLibrary
+}
+
+// NewClusterAdapter creates a new cluster adapter with the given maximum
+// weighted-average Hamming distance to a cluster representative. labels may be
+// nil when no label library is configured.
+func NewClusterAdapter(threshold int, weights cluster.Weights, labels *cluster.LabelLibrary) *ClusterAdapter {
+	if threshold < 0 {
+		threshold = cluster.DefaultThreshold
+	}
+	return &ClusterAdapter{threshold: threshold, weights: weights, labels: labels}
+}
+
+// Cluster sets GroupID, PHashScore and ClusterLabel on every result. The group
+// is identified by the URL of its medoid. Results without a usable pHash form
+// their own single-member group.
+func (a *ClusterAdapter) Cluster(ctx context.Context, results []domain.ScanResult) error {
+	start := time.Now()
+
+	var items []cluster.Item
+	members := make(map[string]cluster.Member)
+	for _, res := range results {
+		phash, err := cluster.ParseHash(res.PHash)
+		if err != nil {
+			continue
+		}
+		it := cluster.Item{ID: res.Target.URL}
+		it.Set(cluster.SignalPHash, phash)
+		// Signals that failed to compute are left out rather than compared
+		// as zero.
+		for sig, raw := range map[cluster.Signal]string{
+			cluster.SignalDHash: res.Hashes.DHash,
+			cluster.SignalAHash: res.Hashes.AHash,
+			cluster.SignalDOM:   res.Hashes.DOMHash,
+			cluster.SignalText:  res.Hashes.TextHash,
+		} {
+			if h, err := cluster.ParseHash(raw); err == nil {
+				it.Set(sig, h)
+			}
+		}
+		items = append(items, it)
+
+		var techs []string
+		for _, t := range res.Metadata.Technologies {
+			techs = append(techs, t.Name)
+		}
+		members[it.ID] = cluster.Member{Item: it, Title: res.Metadata.Title, Technologies: techs}
+	}
+
+	assignments := cluster.Cluster(items, a.threshold, a.weights)
+
+	byLeader := make(map[string][]cluster.Member)
+	for id, as := range assignments {
+		byLeader[as.Leader] = append(byLeader[as.Leader], members[id])
+	}
+	summaries := make(map[string]cluster.Summary, len(byLeader))
+	for leader, group := range byLeader {
+		summaries[leader] = cluster.Summarize(group, a.weights, a.labels)
+	}
+
+	for i := range results {
+		res := &results[i]
+		as, ok := assignments[res.Target.URL]
+		if !ok {
+			res.GroupID = res.Target.URL
+			res.PHashScore = 0
+			res.ClusterLabel = ""
+			continue
+		}
+		sum := summaries[as.Leader]
+		res.GroupID = sum.Representative
+		res.PHashScore = uint64(sum.Distances[res.Target.URL])
+		res.ClusterLabel = sum.Label
+	}
+
+	slog.Info("Clustering complete", "duration", time.Since(start), "groups", len(byLeader)+len(results)-len(assignments))
+	return nil
+}
@@ -0,0 +1,50 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// JSONLAdapter streams each finished result as one line of JSON, in the
+// results.json format, as soon as the target is done. Cluster fields are
+// only known once the whole scan is clustered, so they stay empty.
+type JSONLAdapter struct {
+	outputPath string
+	logger     *slog.Logger
+
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool
+}
+
+// NewJSONLAdapter creates an adapter writing to w. Screenshot paths are made
+// relative to outputPath, like in results.json.
+func NewJSONLAdapter(w io.Writer, outputPath string, logger *slog.Logger) *JSONLAdapter {
+	return &JSONLAdapter{outputPath: outputPath, logger: logger, enc: json.NewEncoder(w)}
+}
+
+// HandleEvent implements ports.EventSubscriber.
+func (a *JSONLAdapter) HandleEvent(ctx context.Context, event domain.Event) {
+	if event.Type != domain.EventTargetFinished || event.Result == nil {
+		return
+	}
+	line := legacyTarget(*event.Result, func(path string) string {
+		return relativeTo(a.outputPath, path)
+	})
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failed {
+		return
+	}
+	if err := a.enc.Encode(line); err != nil {
+		// The reader went away; keep scanning so the reports are still
+		// written.
+		a.failed = true
+		a.logger.Warn("Stopped streaming results", "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLAdapter_HandleEvent(t *testing.T) {
+	var buf bytes.Buffer
+	out := filepath.Join("reports", "scan")
+	adapter := NewJSONLAdapter(&buf, out, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	alive := &domain.ScanResult{
+		Target:     domain.Target{URL: "http://a.test"},
+		IsAlive:    true,
+		Metadata:   domain.Metadata{Title: "A", StatusCode: 200},
+		Screenshot: filepath.Join(out, "screenshots", "a.png"),
+		Findings:   []domain.Finding{{ID: "missing-csp", Severity: domain.SeverityLow}},
+	}
+	adapter.HandleEvent(ctx, domain.Event{Type: domain.EventTargetAnalyzed, Result: alive})
+	adapter.HandleEvent(ctx, domain.Event{Type: domain.EventTargetFinished, Result: alive})
+	adapter.HandleEvent(ctx, domain.Event{Type: domain.EventTargetFinished, Result: &domain.ScanResult{
+		Target: domain.Target{URL: "http://b.test"},
+		Error:  "connection refused",
+	}})
+	adapter.HandleEvent(ctx, domain.Event{Type: domain.EventScanFinished})
+
+	var lines []models.Target
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line models.Target
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2, "one line per finished target")
+	assert.Equal(t, "http://a.test", lines[0].URL)
+	assert.Equal(t, "A", lines[0].Metadata.Title)
+	assert.Equal(t, "screenshots/a.png", lines[0].Screenshot)
+	assert.Equal(t, "low", lines[0].Findings[0].Severity)
+	assert.Equal(t, "connection refused", lines[1].Error)
+}
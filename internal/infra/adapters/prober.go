@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/ismailtsdln/netvista/internal/core/domain"
@@ -14,10 +15,10 @@ type ProberAdapter struct {
 }
 
 // NewProberAdapter creates a new prober adapter.
-func NewProberAdapter(timeout time.Duration, proxy string, headers map[string]string) *ProberAdapter {
-	return &ProberAdapter{
-		p: prober.NewProber(timeout, proxy, headers, 10), // Default 10 redirects
-	}
+func NewProberAdapter(timeout time.Duration, proxy string, headers map[string]string, logger *slog.Logger) *ProberAdapter {
+	p := prober.NewProber(timeout, proxy, headers, 10) // Default 10 redirects
+	p.Logger = logger
+	return &ProberAdapter{p: p}
 }
 
 // Probe extracts metadata from a target.
@@ -27,13 +28,32 @@ func (a *ProberAdapter) Probe(ctx context.Context, target domain.Target) (*domai
 		return nil, "", err
 	}
 
+	var forms []domain.Form
+	for _, f := range res.Metadata.Forms {
+		forms = append(forms, domain.Form{
+			Action:      f.Action,
+			Method:      f.Method,
+			Inputs:      f.Inputs,
+			HasPassword: f.HasPassword,
+		})
+	}
+
 	return &domain.Metadata{
-		Title:      res.Metadata.Title,
-		StatusCode: res.Metadata.StatusCode,
-		Technology: res.Metadata.Technology,
-		Headers:    res.Metadata.Headers,
-		ContentLen: res.Metadata.ContentLen,
-		Timestamp:  res.Metadata.Timestamp,
-		Redirects:  res.Metadata.Redirects,
+		Title:       res.Metadata.Title,
+		StatusCode:  res.Metadata.StatusCode,
+		Technology:  res.Metadata.Technology,
+		Headers:     res.Metadata.Headers,
+		Cookies:     res.Metadata.Cookies,
+		ContentLen:  res.Metadata.ContentLen,
+		Timestamp:   res.Metadata.Timestamp,
+		Redirects:   res.Metadata.Redirects,
+		Description: res.Metadata.Description,
+		Generator:   res.Metadata.Generator,
+		Canonical:   res.Metadata.Canonical,
+		Forms:       forms,
+		Scripts:     res.Metadata.Scripts,
+		Links:       res.Metadata.Links,
+		Iframes:     res.Metadata.Iframes,
+		Body:        res.Metadata.Body,
 	}, res.URL, nil
 }
@@ -2,6 +2,8 @@ package adapters
 
 import (
 	"context"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -20,7 +22,7 @@ func TestProberAdapter_Probe(t *testing.T) {
 	}))
 	defer server.Close()
 
-	adapter := NewProberAdapter(2*time.Second, "", nil)
+	adapter := NewProberAdapter(2*time.Second, "", nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	target := domain.Target{URL: server.URL}
 
 	metadata, resolvedURL, err := adapter.Probe(context.Background(), target)
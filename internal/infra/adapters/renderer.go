@@ -6,6 +6,7 @@ import (
 
 	"github.com/ismailtsdln/netvista/internal/core/domain"
 	"github.com/ismailtsdln/netvista/internal/screenshot"
+	"github.com/ismailtsdln/netvista/pkg/consent"
 	"github.com/ismailtsdln/netvista/pkg/utils"
 )
 
@@ -15,23 +16,42 @@ type RendererAdapter struct {
 }
 
 // NewRendererAdapter creates a new renderer adapter.
-func NewRendererAdapter(outputPath string, proxy string, fullPage bool, maxContexts int) (*RendererAdapter, error) {
-	c, err := screenshot.NewCapturer(outputPath, proxy, maxContexts)
+func NewRendererAdapter(outputPath string, proxy string, fullPage bool, maxContexts int, consentRules *consent.Rules, imgOpts screenshot.ImageOptions) (*RendererAdapter, error) {
+	c, err := screenshot.NewCapturer(outputPath, proxy, maxContexts, consentRules, imgOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize capturer: %w", err)
 	}
 	return &RendererAdapter{c: c}, nil
 }
 
-// Render captures a screenshot and returns the path, pHash, and framework.
-func (a *RendererAdapter) Render(ctx context.Context, target domain.Target) (string, string, string, error) {
-	// Clean filename for filesystem compatibility
-	filename := fmt.Sprintf("%s.png", utils.SanitizeFilename(target.URL))
-	res, err := a.c.Capture(ctx, target.URL, filename)
+// Render captures a screenshot and returns the path, pHash, and detected frameworks.
+func (a *RendererAdapter) Render(ctx context.Context, target domain.Target) (*domain.RenderResult, error) {
+	// Collision-free filename derived from the URL
+	res, err := a.c.Capture(ctx, target.URL, utils.ScreenshotName(target.URL))
 	if err != nil {
-		return "", "", "", err
+		return nil, err
 	}
-	return res.Path, res.PHash, res.Framework, nil
+
+	rendered := &domain.RenderResult{
+		Path:      res.Path,
+		Thumbnail: res.Thumbnail,
+		PHash:     res.PHash,
+		Hashes: domain.PageHashes{
+			DHash:    res.DHash,
+			AHash:    res.AHash,
+			DOMHash:  res.DOMHash,
+			TextHash: res.TextHash,
+		},
+		ConsentRule: res.ConsentRule,
+	}
+	for _, fw := range res.Frameworks {
+		rendered.Technologies = append(rendered.Technologies, domain.Technology{
+			Name:    fw.Name,
+			Version: fw.Version,
+			Source:  "rendered",
+		})
+	}
+	return rendered, nil
 }
 
 // Close releases browser resources.
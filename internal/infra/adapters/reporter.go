@@ -16,36 +16,43 @@ type ReporterAdapter struct {
 	enableCSV  bool
 	enableMD   bool
 	enableTXT  bool
+	singleFile bool
 }
 
-// NewReporterAdapter creates a new reporter adapter.
-func NewReporterAdapter(outputPath string, enableCSV, enableMD, enableTXT bool) *ReporterAdapter {
+// NewReporterAdapter creates a new reporter adapter. With singleFile the HTML
+// report embeds its assets and thumbnails so it works offline on its own.
+func NewReporterAdapter(outputPath string, enableCSV, enableMD, enableTXT, singleFile bool) *ReporterAdapter {
 	return &ReporterAdapter{
 		outputPath: outputPath,
 		enableCSV:  enableCSV,
 		enableMD:   enableMD,
 		enableTXT:  enableTXT,
+		singleFile: singleFile,
 	}
 }
 
+// relPath makes a screenshot path relative to the report directory so the
+// HTML report keeps working when the directory is moved or served.
+func (a *ReporterAdapter) relPath(path string) string {
+	return relativeTo(a.outputPath, path)
+}
+
+func relativeTo(dir, path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
 // Report generates reports based on scan results.
 func (a *ReporterAdapter) Report(ctx context.Context, results []domain.ScanResult) error {
 	// Map domain results back to legacy models for report compatibility
 	var legacyResults []models.Target
 	for _, res := range results {
-		legacyResults = append(legacyResults, models.Target{
-			URL: res.Target.URL,
-			Metadata: models.ResponseMetadata{
-				Title:      res.Metadata.Title,
-				StatusCode: res.Metadata.StatusCode,
-				Technology: res.Metadata.Technology,
-				Headers:    res.Metadata.Headers,
-				ContentLen: res.Metadata.ContentLen,
-				Redirects:  res.Metadata.Redirects,
-				Timestamp:  res.Metadata.Timestamp,
-			},
-			PHash: res.PHash,
-		})
+		legacyResults = append(legacyResults, legacyTarget(res, a.relPath))
 	}
 
 	// 1. JSON Export
@@ -73,7 +80,7 @@ func (a *ReporterAdapter) Report(ctx context.Context, results []domain.ScanResul
 	// 5. HTML Export
 	templatePath := "web/templates/dashboard.html"
 	htmlPath := filepath.Join(a.outputPath, "report.html")
-	if err := report.GenerateHTML(legacyResults, templatePath, htmlPath); err != nil {
+	if err := report.GenerateHTML(legacyResults, templatePath, htmlPath, report.HTMLOptions{SingleFile: a.singleFile}); err != nil {
 		return fmt.Errorf("failed to generate HTML report: %w", err)
 	}
 
@@ -83,3 +90,89 @@ func (a *ReporterAdapter) Report(ctx context.Context, results []domain.ScanResul
 
 	return nil
 }
+
+// legacyTarget maps a domain result back to the legacy model written to
+// results.json. relPath makes screenshot paths relative to the report
+// directory.
+func legacyTarget(res domain.ScanResult, relPath func(string) string) models.Target {
+	var forms []models.Form
+	for _, f := range res.Metadata.Forms {
+		forms = append(forms, models.Form{Action: f.Action, Method: f.Method, Inputs: f.Inputs, HasPassword: f.HasPassword})
+	}
+	var techs []models.Technology
+	for _, t := range res.Metadata.Technologies {
+		techs = append(techs, models.Technology{Name: t.Name, Version: t.Version, Source: t.Source})
+	}
+	var findings []models.Finding
+	for _, f := range res.Findings {
+		findings = append(findings, models.Finding{
+			Analyzer:   f.Analyzer,
+			ID:         f.ID,
+			Title:      f.Title,
+			Severity:   string(f.Severity),
+			Confidence: f.Confidence,
+			Evidence:   f.Evidence,
+			Location:   f.Location,
+			Line:       f.Line,
+			Offset:     f.Offset,
+		})
+	}
+	var endpoints []models.Endpoint
+	for _, e := range res.Endpoints {
+		endpoints = append(endpoints, models.Endpoint{URL: e.URL, Kind: e.Kind, Source: e.Source})
+	}
+	var paths []models.PathHit
+	for _, h := range res.Paths {
+		paths = append(paths, models.PathHit{
+			URL:        h.URL,
+			Path:       h.Path,
+			StatusCode: h.StatusCode,
+			Length:     h.Length,
+			Title:      h.Title,
+			Screenshot: relPath(h.Screenshot),
+			Thumbnail:  relPath(h.Thumbnail),
+		})
+	}
+	return models.Target{
+		URL:     res.Target.URL,
+		IsAlive: res.IsAlive,
+		Error:   res.Error,
+		Metadata: models.ResponseMetadata{
+			Title:        res.Metadata.Title,
+			StatusCode:   res.Metadata.StatusCode,
+			Technology:   res.Metadata.Technology,
+			Technologies: techs,
+			Headers:      res.Metadata.Headers,
+			ContentLen:   res.Metadata.ContentLen,
+			Redirects:    res.Metadata.Redirects,
+			Timestamp:    res.Metadata.Timestamp,
+			Description:  res.Metadata.Description,
+			Generator:    res.Metadata.Generator,
+			Canonical:    res.Metadata.Canonical,
+			Forms:        forms,
+			Scripts:      res.Metadata.Scripts,
+			Links:        res.Metadata.Links,
+			Iframes:      res.Metadata.Iframes,
+		},
+		PHash: res.PHash,
+		Hashes: models.PageHashes{
+			DHash:    res.Hashes.DHash,
+			AHash:    res.Hashes.AHash,
+			DOMHash:  res.Hashes.DOMHash,
+			TextHash: res.Hashes.TextHash,
+		},
+		Screenshot:    relPath(res.Screenshot),
+		Thumbnail:     relPath(res.Thumbnail),
+		GroupID:       res.GroupID,
+		PHashScore:    res.PHashScore,
+		ClusterLabel:  res.ClusterLabel,
+		ConsentRule:   res.ConsentRule,
+		PageClass:     res.PageClass,
+		Findings:      findings,
+		SecurityScore: res.SecurityScore,
+		SecurityGrade: res.SecurityGrade,
+		Endpoints:     endpoints,
+		Paths:         paths,
+		CNAMEs:        res.CNAMEs,
+	}
+}
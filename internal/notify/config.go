@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is a condition that triggers a notification.
+type Kind string
+
+const (
+	KindScanFinished      Kind = "scan_finished"      // A scan completed, with a summary
+	KindNewHost           Kind = "new_host"           // A URL missing from the baseline answered
+	KindTakeover          Kind = "takeover"           // A subdomain takeover candidate was found
+	KindLoginPage         Kind = "login_page"         // A page became a login page
+	KindScreenshotChanged Kind = "screenshot_changed" // A page looks different from the baseline
+)
+
+// Kinds lists every condition in the order they are documented.
+var Kinds = []Kind{KindScanFinished, KindNewHost, KindTakeover, KindLoginPage, KindScreenshotChanged}
+
+// Payload formats.
+const (
+	FormatJSON       = "json"
+	FormatSlack      = "slack"
+	FormatDiscord    = "discord"
+	FormatTeams      = "teams"
+	FormatMattermost = "mattermost"
+)
+
+// Retry defaults.
+const (
+	defaultAttempts = 3
+	defaultBackoff  = time.Second
+	maxBackoff      = 30 * time.Second
+)
+
+// defaultScreenshotDistance is the pHash Hamming distance above which a
+// screenshot counts as changed.
+const defaultScreenshotDistance = 10
+
+// defaultTemplates are the messages sent when a webhook sets no template
+// of its own for a condition.
+var defaultTemplates = map[Kind]string{
+	KindScanFinished:      `NetVista scan finished: {{.Summary.Total}} targets, {{.Summary.Alive}} alive, {{.Summary.Failed}} failed, {{.Summary.Findings}} findings in {{.Summary.Duration}}{{with .Summary.Error}} ({{.}}){{end}}`,
+	KindNewHost:           `New host: {{.URL}}{{with .Title}} "{{.}}"{{end}} [{{.StatusCode}}]`,
+	KindTakeover:          `Possible subdomain takeover: {{.URL}}{{range .Findings}} - {{.Title}}: {{.Evidence}}{{end}}`,
+	KindLoginPage:         `New login page: {{.URL}}{{with .Title}} "{{.}}"{{end}}`,
+	KindScreenshotChanged: `Screenshot changed: {{.URL}} (pHash distance {{.Distance}})`,
+}
+
+// Config is the notification file: webhooks plus the baseline that the
+// change conditions compare against.
+type Config struct {
+	// Baseline is a results.json (or a report directory holding one) from
+	// an earlier scan.
+	Baseline string `yaml:"baseline"`
+	// ScreenshotDistance is the pHash distance above which a screenshot
+	// counts as changed.
+	ScreenshotDistance int       `yaml:"screenshot_distance"`
+	Webhooks           []Webhook `yaml:"webhooks"`
+}
+
+// Webhook is one notification endpoint.
+type Webhook struct {
+	Name    string            `yaml:"name"`
+	Format  string            `yaml:"format"` // json (default), slack, discord, teams or mattermost
+	URL     string            `yaml:"url"`
+	Events  []Kind            `yaml:"events"` // Empty means every condition
+	Headers map[string]string `yaml:"headers"`
+	// Templates override the message of a condition. They are Go
+	// text/templates executed with the Notification.
+	Templates map[Kind]string `yaml:"templates"`
+	// AttachScreenshot sends the page screenshot with target notifications:
+	// inline for json and teams, as an uploaded file for discord. Slack and
+	// Mattermost can only show images by link, so they need
+	// ScreenshotBaseURL.
+	AttachScreenshot bool `yaml:"attach_screenshot"`
+	// ScreenshotBaseURL is where the report directory is published; the
+	// screenshot's relative path is appended to link the image.
+	ScreenshotBaseURL string        `yaml:"screenshot_base_url"`
+	Attempts          int           `yaml:"attempts"`
+	Backoff           time.Duration `yaml:"backoff"` // First retry delay, doubled after each attempt
+	Timeout           time.Duration `yaml:"timeout"`
+
+	templates map[Kind]*template.Template
+	events    map[Kind]bool
+}
+
+// LoadConfig reads and validates a notification file.
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// validate checks the config, fills in defaults and parses templates.
+func (c *Config) validate() error {
+	if len(c.Webhooks) == 0 {
+		return fmt.Errorf("no webhooks")
+	}
+	if c.ScreenshotDistance <= 0 {
+		c.ScreenshotDistance = defaultScreenshotDistance
+	}
+	for i := range c.Webhooks {
+		w := &c.Webhooks[i]
+		if w.Name == "" {
+			w.Name = fmt.Sprintf("webhook %d", i+1)
+		}
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("%s: %w", w.Name, err)
+		}
+	}
+	return nil
+}
+
+func (w *Webhook) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q", w.URL)
+	}
+	switch w.Format {
+	case "":
+		w.Format = FormatJSON
+	case FormatJSON, FormatDiscord, FormatTeams:
+	case FormatSlack, FormatMattermost:
+		if w.AttachScreenshot && w.ScreenshotBaseURL == "" {
+			return fmt.Errorf("%s shows screenshots by link; set screenshot_base_url", w.Format)
+		}
+	default:
+		return fmt.Errorf("unknown format %q", w.Format)
+	}
+	if w.Attempts <= 0 {
+		w.Attempts = defaultAttempts
+	}
+	if w.Backoff <= 0 {
+		w.Backoff = defaultBackoff
+	}
+	if w.Timeout <= 0 {
+		w.Timeout = 10 * time.Second
+	}
+
+	w.events = make(map[Kind]bool)
+	for _, k := range w.Events {
+		if defaultTemplates[k] == "" {
+			return fmt.Errorf("unknown event %q", k)
+		}
+		w.events[k] = true
+	}
+	w.templates = make(map[Kind]*template.Template)
+	for _, k := range Kinds {
+		text := defaultTemplates[k]
+		if custom, ok := w.Templates[k]; ok {
+			text = custom
+		}
+		t, err := template.New(string(k)).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return fmt.Errorf("template %s: %w", k, err)
+		}
+		w.templates[k] = t
+	}
+	for k := range w.Templates {
+		if defaultTemplates[k] == "" {
+			return fmt.Errorf("template for unknown event %q", k)
+		}
+	}
+	return nil
+}
+
+// wants reports whether the webhook subscribed to a condition.
+func (w *Webhook) wants(k Kind) bool {
+	return len(w.events) == 0 || w.events[k]
+}
@@ -0,0 +1,275 @@
+// Package notify posts webhook notifications when a scan finishes or when a
+// result meets a watched condition: a new host, a takeover candidate, a new
+// login page or a changed screenshot.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/analyzers"
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/internal/screenshot"
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+// Notification is what a webhook receives. Message templates are executed
+// with it, and the json format sends it as is.
+type Notification struct {
+	Event      Kind             `json:"event"`
+	Time       time.Time        `json:"time"`
+	Text       string           `json:"text"`
+	URL        string           `json:"url,omitempty"`
+	Title      string           `json:"title,omitempty"`
+	StatusCode int              `json:"status_code,omitempty"`
+	PageClass  string           `json:"page_class,omitempty"`
+	Findings   []models.Finding `json:"findings,omitempty"`
+	Distance   int              `json:"distance,omitempty"` // pHash distance to the baseline screenshot
+	Screenshot string           `json:"screenshot,omitempty"`
+	Previous   *models.Target   `json:"-"` // The baseline result of the URL, if any
+	Summary    *Summary         `json:"summary,omitempty"`
+
+	screenshotPath string
+}
+
+// Summary describes a finished scan.
+type Summary struct {
+	Total    int    `json:"total"`
+	Alive    int    `json:"alive"`
+	Failed   int    `json:"failed"`
+	Findings int    `json:"findings"`
+	Alerts   int    `json:"alerts"` // Notifications sent for individual targets
+	Duration string `json:"duration"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Notifier turns scan events into webhook notifications. Register it with
+// ScannerService.Subscribe so that slow webhooks never hold up the scan.
+type Notifier struct {
+	cfg      *Config
+	output   string
+	baseline map[string]models.Target
+	client   *httpClient
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	started time.Time
+	summary Summary
+}
+
+// New creates a notifier for a scan writing to output. The baseline is the
+// one named in cfg, or else output's existing results.json; it must be
+// loaded before the scan overwrites it. The scan has to include the
+// baseline's URLs for the change conditions to see them, so the scan
+// command turns off incremental skipping when notifications are on.
+func New(cfg *Config, output string, logger *slog.Logger) (*Notifier, error) {
+	n := &Notifier{cfg: cfg, output: output, client: newHTTPClient(), logger: logger}
+	path := cfg.Baseline
+	if path == "" && output != "" {
+		path = filepath.Join(output, "results.json")
+		if _, err := os.Stat(path); err != nil {
+			return n, nil
+		}
+	}
+	if path != "" {
+		baseline, err := LoadBaseline(path)
+		if err != nil {
+			return nil, err
+		}
+		n.baseline = baseline
+		logger.Info("Loaded notification baseline", "path", path, "count", len(baseline))
+	}
+	return n, nil
+}
+
+// LoadBaseline reads the results of an earlier scan, keyed by URL. Path is
+// a results.json or a report directory holding one.
+func LoadBaseline(path string) (map[string]models.Target, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "results.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load baseline: %w", err)
+	}
+	var results []models.Target
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("load baseline %s: %w", path, err)
+	}
+	baseline := make(map[string]models.Target, len(results))
+	for _, r := range results {
+		baseline[r.URL] = r
+	}
+	return baseline, nil
+}
+
+// HandleEvent implements ports.EventSubscriber.
+func (n *Notifier) HandleEvent(ctx context.Context, e domain.Event) {
+	switch e.Type {
+	case domain.EventTargetsQueued:
+		n.mu.Lock()
+		if n.started.IsZero() {
+			n.started = e.Time
+		}
+		n.summary.Total += e.Total
+		n.mu.Unlock()
+	case domain.EventTargetFinished:
+		if e.Result == nil {
+			return
+		}
+		notes := n.evaluate(e.Result)
+		n.mu.Lock()
+		if e.Result.IsAlive {
+			n.summary.Alive++
+		}
+		if e.Result.Error != "" {
+			n.summary.Failed++
+		}
+		n.summary.Findings += len(e.Result.Findings)
+		n.summary.Alerts += len(notes)
+		n.mu.Unlock()
+		for _, note := range notes {
+			note.Time = e.Time
+			n.Notify(ctx, note)
+		}
+	case domain.EventScanFinished:
+		n.mu.Lock()
+		summary := n.summary
+		if !n.started.IsZero() {
+			summary.Duration = e.Time.Sub(n.started).Round(time.Second).String()
+		}
+		n.mu.Unlock()
+		summary.Output = n.output
+		if e.Err != nil {
+			summary.Error = e.Err.Error()
+		}
+		n.Notify(ctx, Notification{Event: KindScanFinished, Time: e.Time, Summary: &summary})
+	}
+}
+
+// evaluate returns the notifications a finished result triggers. Conditions
+// that describe a change need a baseline; without one only takeover
+// candidates and login pages are reported. Takeover candidates are reported
+// for dead hosts too, since a dangling CNAME usually makes the probe fail.
+func (n *Notifier) evaluate(res *domain.ScanResult) []Notification {
+	prev, known := n.baseline[res.Target.URL]
+	base := Notification{
+		URL:        res.Target.URL,
+		Title:      res.Metadata.Title,
+		StatusCode: res.Metadata.StatusCode,
+		PageClass:  res.PageClass,
+		Screenshot: n.relPath(res.Screenshot),
+	}
+	if known {
+		base.Previous = &prev
+	}
+	base.screenshotPath = res.Screenshot
+
+	var notes []Notification
+	add := func(k Kind, edit func(*Notification)) {
+		note := base
+		note.Event = k
+		if edit != nil {
+			edit(&note)
+		}
+		notes = append(notes, note)
+	}
+
+	if res.IsAlive && n.baseline != nil && !known {
+		add(KindNewHost, nil)
+	}
+	if findings := n.takeoverFindings(res, prev); len(findings) > 0 {
+		add(KindTakeover, func(note *Notification) { note.Findings = findings })
+	}
+	if res.IsAlive && res.PageClass == analyzers.ClassLogin && prev.PageClass != analyzers.ClassLogin {
+		add(KindLoginPage, nil)
+	}
+	if res.IsAlive && known && res.PHash != "" && prev.PHash != "" {
+		if d, err := screenshot.HammingDistance(res.PHash, prev.PHash); err == nil && d > n.cfg.ScreenshotDistance {
+			add(KindScreenshotChanged, func(note *Notification) { note.Distance = d })
+		}
+	}
+	return notes
+}
+
+// takeoverFindings returns the takeover findings of res that the baseline
+// result prev did not already have.
+func (n *Notifier) takeoverFindings(res *domain.ScanResult, prev models.Target) []models.Finding {
+	seen := make(map[string]bool)
+	for _, f := range prev.Findings {
+		seen[f.ID] = true
+	}
+	var out []models.Finding
+	for _, f := range res.Findings {
+		if (f.ID != analyzers.FindingTakeover && f.ID != analyzers.FindingDanglingCNAME) || seen[f.ID] {
+			continue
+		}
+		out = append(out, models.Finding{
+			Analyzer:   f.Analyzer,
+			ID:         f.ID,
+			Title:      f.Title,
+			Severity:   string(f.Severity),
+			Confidence: f.Confidence,
+			Evidence:   f.Evidence,
+			Location:   f.Location,
+		})
+	}
+	return out
+}
+
+// relPath makes a screenshot path relative to the report directory, the
+// form used in results.json and in screenshot links.
+func (n *Notifier) relPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if rel, err := filepath.Rel(n.output, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// Notify sends a notification to every webhook subscribed to its condition.
+// Failures are logged once the retries are used up.
+func (n *Notifier) Notify(ctx context.Context, note Notification) {
+	for i := range n.cfg.Webhooks {
+		w := &n.cfg.Webhooks[i]
+		if !w.wants(note.Event) {
+			continue
+		}
+		if err := n.send(ctx, w, note); err != nil {
+			n.logger.Warn("Notification failed", "webhook", w.Name, "event", note.Event, "url", note.URL, "error", err)
+		}
+	}
+}
+
+// send renders the message for one webhook and delivers it.
+func (n *Notifier) send(ctx context.Context, w *Webhook, note Notification) error {
+	var text strings.Builder
+	if err := w.templates[note.Event].Execute(&text, note); err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+	note.Text = text.String()
+	var shot *attachment
+	if w.AttachScreenshot && note.screenshotPath != "" {
+		a, err := readAttachment(note.screenshotPath)
+		if err != nil {
+			n.logger.Warn("Screenshot not attached", "webhook", w.Name, "path", note.screenshotPath, "error", err)
+		} else {
+			shot = a
+		}
+	}
+	body, contentType, err := buildPayload(w, note, shot)
+	if err != nil {
+		return err
+	}
+	return n.client.post(ctx, w, body, contentType)
+}
@@ -0,0 +1,287 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ismailtsdln/netvista/internal/analyzers"
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+// hook is a local stand-in for a webhook endpoint.
+type hook struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int // Returned in order; 200 once used up
+}
+
+func (h *hook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r)
+	h.bodies = append(h.bodies, body)
+	if len(h.statuses) > 0 {
+		status := h.statuses[0]
+		h.statuses = h.statuses[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "7")
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func (h *hook) messages(t *testing.T) []map[string]any {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []map[string]any
+	for _, b := range h.bodies {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(b, &m))
+		out = append(out, m)
+	}
+	return out
+}
+
+func newNotifier(t *testing.T, output string, webhooks ...Webhook) *Notifier {
+	t.Helper()
+	cfg := &Config{Webhooks: webhooks}
+	require.NoError(t, cfg.validate())
+	n, err := New(cfg, output, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	n.client.sleep = func(context.Context, time.Duration) error { return nil }
+	return n
+}
+
+func writeBaseline(t *testing.T, dir string, results []models.Target) {
+	t.Helper()
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "results.json"), data, 0o644))
+}
+
+func finished(res domain.ScanResult) domain.Event {
+	return domain.Event{Type: domain.EventTargetFinished, Time: time.Now(), Target: res.Target, Result: &res}
+}
+
+func TestNotifier_Conditions(t *testing.T) {
+	h := &hook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeBaseline(t, dir, []models.Target{
+		{URL: "http://known.test", IsAlive: true, PHash: "p:0000000000000000"},
+		{URL: "http://login.test", IsAlive: true, PageClass: analyzers.ClassLogin},
+	})
+	n := newNotifier(t, dir, Webhook{URL: srv.URL})
+	ctx := context.Background()
+
+	n.HandleEvent(ctx, domain.Event{Type: domain.EventTargetsQueued, Time: time.Now(), Total: 4})
+	n.HandleEvent(ctx, finished(domain.ScanResult{
+		Target: domain.Target{URL: "http://known.test"}, IsAlive: true, PHash: "p:00000000000fffff",
+	}))
+	n.HandleEvent(ctx, finished(domain.ScanResult{
+		Target: domain.Target{URL: "http://login.test"}, IsAlive: true, PageClass: analyzers.ClassLogin,
+	}))
+	n.HandleEvent(ctx, finished(domain.ScanResult{
+		Target: domain.Target{URL: "http://new.test"}, IsAlive: true, PageClass: analyzers.ClassLogin,
+		Metadata: domain.Metadata{Title: "Sign in", StatusCode: 200},
+		Findings: []domain.Finding{{ID: analyzers.FindingTakeover, Title: "Possible subdomain takeover", Evidence: "new.test -> x.github.io"}},
+	}))
+	n.HandleEvent(ctx, finished(domain.ScanResult{Target: domain.Target{URL: "http://down.test"}, Error: "connection refused"}))
+	n.HandleEvent(ctx, domain.Event{Type: domain.EventScanFinished, Time: time.Now()})
+
+	msgs := h.messages(t)
+	var events []string
+	for _, m := range msgs {
+		events = append(events, m["event"].(string))
+	}
+	assert.Equal(t, []string{"screenshot_changed", "new_host", "takeover", "login_page", "scan_finished"}, events)
+
+	assert.Equal(t, "Screenshot changed: http://known.test (pHash distance 20)", msgs[0]["text"])
+	assert.Equal(t, `New host: http://new.test "Sign in" [200]`, msgs[1]["text"])
+	assert.Contains(t, msgs[2]["text"], "new.test -> x.github.io")
+	summary := msgs[4]["summary"].(map[string]any)
+	assert.EqualValues(t, 4, summary["total"])
+	assert.EqualValues(t, 3, summary["alive"])
+	assert.EqualValues(t, 1, summary["failed"])
+	assert.EqualValues(t, 4, summary["alerts"])
+	assert.Equal(t, "application/json", h.requests[0].Header.Get("Content-Type"))
+}
+
+func TestNotifier_NoBaseline(t *testing.T) {
+	h := &hook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	n := newNotifier(t, t.TempDir(), Webhook{
+		URL:       srv.URL,
+		Events:    []Kind{KindNewHost, KindLoginPage},
+		Templates: map[Kind]string{KindLoginPage: "login {{.URL}} was {{with .Previous}}{{.PageClass}}{{else}}unknown{{end}}"},
+		Headers:   map[string]string{"X-Token": "secret"},
+	})
+	n.HandleEvent(context.Background(), finished(domain.ScanResult{
+		Target: domain.Target{URL: "http://a.test"}, IsAlive: true, PageClass: analyzers.ClassLogin,
+	}))
+	n.HandleEvent(context.Background(), domain.Event{Type: domain.EventScanFinished, Time: time.Now()})
+
+	msgs := h.messages(t)
+	require.Len(t, msgs, 1, "new_host needs a baseline and scan_finished is filtered out")
+	assert.Equal(t, "login http://a.test was unknown", msgs[0]["text"])
+	assert.Equal(t, "secret", h.requests[0].Header.Get("X-Token"))
+}
+
+func TestNotifier_TakeoverOnDeadHost(t *testing.T) {
+	h := &hook{}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeBaseline(t, dir, []models.Target{{URL: "http://known.test", IsAlive: true}})
+	n := newNotifier(t, dir, Webhook{URL: srv.URL})
+	n.HandleEvent(context.Background(), finished(domain.ScanResult{
+		Target: domain.Target{URL: "http://gone.test"},
+		Error:  "dial tcp: lookup gone.test: no such host",
+		Findings: []domain.Finding{{
+			ID: analyzers.FindingTakeover, Title: "Possible subdomain takeover (GitHub Pages)",
+			Severity: domain.SeverityHigh, Confidence: analyzers.ConfidenceHigh, Evidence: "gone.test -> x.github.io (NXDOMAIN)",
+		}},
+	}))
+
+	msgs := h.messages(t)
+	require.Len(t, msgs, 1, "a dead host is not a new host but still a takeover candidate")
+	assert.Equal(t, "takeover", msgs[0]["event"])
+	assert.Equal(t, "high", msgs[0]["findings"].([]any)[0].(map[string]any)["confidence"])
+}
+
+func TestBuildPayload_Formats(t *testing.T) {
+	shot := &attachment{name: "a.png", contentType: "image/png", data: []byte("PNG")}
+	note := Notification{Event: KindNewHost, Text: "New host: http://a.test", URL: "http://a.test", Screenshot: "screenshots/a.png"}
+	decode := func(body []byte) map[string]any {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(body, &m))
+		return m
+	}
+
+	body, ct, err := buildPayload(&Webhook{Format: FormatJSON}, note, shot)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", ct)
+	m := decode(body)
+	assert.Equal(t, "new_host", m["event"])
+	assert.Equal(t, "UE5H", m["image"])
+
+	slack := &Webhook{Format: FormatSlack, AttachScreenshot: true, ScreenshotBaseURL: "https://reports.example/scan1/"}
+	body, _, err = buildPayload(slack, note, nil)
+	require.NoError(t, err)
+	m = decode(body)
+	assert.Equal(t, note.Text, m["text"])
+	blocks := m["blocks"].([]any)
+	assert.Equal(t, "https://reports.example/scan1/screenshots/a.png", blocks[1].(map[string]any)["image_url"])
+
+	body, _, err = buildPayload(&Webhook{Format: FormatMattermost}, note, nil)
+	require.NoError(t, err)
+	m = decode(body)
+	assert.Equal(t, "NetVista", m["username"])
+	assert.NotContains(t, m, "attachments")
+
+	body, _, err = buildPayload(&Webhook{Format: FormatTeams}, note, shot)
+	require.NoError(t, err)
+	m = decode(body)
+	card := m["attachments"].([]any)[0].(map[string]any)["content"].(map[string]any)
+	assert.Equal(t, "AdaptiveCard", card["type"])
+	image := card["body"].([]any)[1].(map[string]any)
+	assert.Equal(t, "data:image/png;base64,UE5H", image["url"])
+
+	body, ct, err = buildPayload(&Webhook{Format: FormatDiscord}, note, shot)
+	require.NoError(t, err)
+	_, params, err := mime.ParseMediaType(ct)
+	require.NoError(t, err)
+	form, err := multipart.NewReader(strings.NewReader(string(body)), params["boundary"]).ReadForm(1 << 20)
+	require.NoError(t, err)
+	assert.Equal(t, note.Text, decode([]byte(form.Value["payload_json"][0]))["content"])
+	require.Len(t, form.File["files[0]"], 1)
+	assert.Equal(t, "a.png", form.File["files[0]"][0].Filename)
+}
+
+func TestNotifier_Retry(t *testing.T) {
+	h := &hook{statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	n := newNotifier(t, "", Webhook{URL: srv.URL, Format: FormatSlack, Backoff: 10 * time.Millisecond})
+	var waits []time.Duration
+	n.client.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	w := &n.cfg.Webhooks[0]
+	require.NoError(t, n.send(context.Background(), w, Notification{Event: KindScanFinished, Summary: &Summary{}}))
+	assert.Len(t, h.requests, 3)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 7 * time.Second}, waits, "backoff, then Retry-After")
+
+	h.statuses = []int{http.StatusBadRequest}
+	err := n.send(context.Background(), w, Notification{Event: KindScanFinished, Summary: &Summary{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+	assert.Len(t, h.requests, 4, "client errors are not retried")
+
+	h.statuses = []int{502, 502, 502, 502}
+	require.Error(t, n.send(context.Background(), w, Notification{Event: KindScanFinished, Summary: &Summary{}}))
+	assert.Len(t, h.requests, 7, "gives up after the default 3 attempts")
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "notify.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	cfg, err := LoadConfig(write(`
+webhooks:
+  - name: ops
+    format: discord
+    url: https://discord.example/api/webhooks/1
+    events: [takeover, scan_finished]
+    backoff: 250ms
+`))
+	require.NoError(t, err)
+	w := cfg.Webhooks[0]
+	assert.Equal(t, 250*time.Millisecond, w.Backoff)
+	assert.Equal(t, defaultAttempts, w.Attempts)
+	assert.Equal(t, defaultScreenshotDistance, cfg.ScreenshotDistance)
+	assert.True(t, w.wants(KindTakeover))
+	assert.False(t, w.wants(KindNewHost))
+
+	for content, want := range map[string]string{
+		`webhooks: []`:                                                          "no webhooks",
+		`webhooks: [{url: "ftp://x"}]`:                                          "invalid url",
+		`webhooks: [{url: "http://x", format: irc}]`:                            "unknown format",
+		`webhooks: [{url: "http://x", events: [reboot]}]`:                       "unknown event",
+		`webhooks: [{url: "http://x", templates: {new_host: "{{.URL"}}]`:        "template new_host",
+		`webhooks: [{url: "http://x", format: slack, attach_screenshot: true}]`: "screenshot_base_url",
+	} {
+		_, err := LoadConfig(write(content))
+		require.Error(t, err, content)
+		assert.Contains(t, err.Error(), want, content)
+	}
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxAttachment is the largest screenshot sent with a notification.
+const maxAttachment = 8 << 20
+
+// username is the sender name shown by chat services that allow one.
+const username = "NetVista"
+
+// attachment is a screenshot sent with a notification.
+type attachment struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+func readAttachment(path string) (*attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxAttachment {
+		return nil, fmt.Errorf("screenshot is %d bytes, limit is %d", info.Size(), maxAttachment)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &attachment{name: filepath.Base(path), contentType: contentType, data: data}, nil
+}
+
+func (a *attachment) dataURI() string {
+	return "data:" + a.contentType + ";base64," + base64.StdEncoding.EncodeToString(a.data)
+}
+
+// imageURL returns the link to a notification's screenshot on the
+// webhook's published report, or "" when there is none.
+func imageURL(w *Webhook, note Notification) string {
+	if w.ScreenshotBaseURL == "" || note.Screenshot == "" || !w.AttachScreenshot {
+		return ""
+	}
+	return strings.TrimRight(w.ScreenshotBaseURL, "/") + "/" + strings.TrimLeft(note.Screenshot, "/")
+}
+
+// buildPayload encodes a notification in the webhook's format and returns
+// the body and its content type.
+func buildPayload(w *Webhook, note Notification, shot *attachment) ([]byte, string, error) {
+	var payload any
+	switch w.Format {
+	case FormatSlack:
+		msg := map[string]any{"text": note.Text}
+		if link := imageURL(w, note); link != "" {
+			msg["blocks"] = []map[string]any{
+				{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": note.Text}},
+				{"type": "image", "image_url": link, "alt_text": note.URL},
+			}
+		}
+		payload = msg
+	case FormatMattermost:
+		msg := map[string]any{"text": note.Text, "username": username}
+		if link := imageURL(w, note); link != "" {
+			msg["attachments"] = []map[string]string{{"fallback": note.URL, "image_url": link}}
+		}
+		payload = msg
+	case FormatDiscord:
+		msg := map[string]any{"content": note.Text, "username": username}
+		if shot != nil {
+			return discordMultipart(msg, shot)
+		}
+		payload = msg
+	case FormatTeams:
+		body := []map[string]any{{"type": "TextBlock", "text": note.Text, "wrap": true}}
+		if shot != nil {
+			body = append(body, map[string]any{"type": "Image", "url": shot.dataURI(), "altText": note.URL})
+		} else if link := imageURL(w, note); link != "" {
+			body = append(body, map[string]any{"type": "Image", "url": link, "altText": note.URL})
+		}
+		payload = map[string]any{
+			"type": "message",
+			"attachments": []map[string]any{{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			}},
+		}
+	default:
+		msg := struct {
+			Notification
+			Image     string `json:"image,omitempty"` // Base64 screenshot
+			ImageType string `json:"image_type,omitempty"`
+		}{Notification: note}
+		if shot != nil {
+			msg.Image = base64.StdEncoding.EncodeToString(shot.data)
+			msg.ImageType = shot.contentType
+		}
+		payload = msg
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, "application/json", nil
+}
+
+// discordMultipart uploads the screenshot as a file next to the message.
+func discordMultipart(msg map[string]any, shot *attachment) ([]byte, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	payloadJSON, err := json.Marshal(msg)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := mw.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return nil, "", err
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[0]"; filename=%q`, shot.name))
+	header.Set("Content-Type", shot.contentType)
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(shot.data); err != nil {
+		return nil, "", err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// httpClient posts payloads, retrying failures with exponential backoff.
+type httpClient struct {
+	client *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func newHTTPClient() *httpClient {
+	return &httpClient{client: &http.Client{}, sleep: sleep}
+}
+
+// post delivers body to the webhook. Network errors, 429 and 5xx responses
+// are retried up to the webhook's attempt limit, honouring Retry-After;
+// other responses are final.
+func (c *httpClient) post(ctx context.Context, w *Webhook, body []byte, contentType string) error {
+	delay := w.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retryAfter time.Duration
+		var retry bool
+		retryAfter, retry, err = c.attempt(ctx, w, body, contentType)
+		if err == nil || !retry || attempt >= w.Attempts {
+			break
+		}
+		wait := delay
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if err := c.sleep(ctx, min(wait, maxBackoff)); err != nil {
+			return err
+		}
+		delay = min(delay*2, maxBackoff)
+	}
+	if err != nil {
+		return fmt.Errorf("after %d attempts: %w", w.Attempts, err)
+	}
+	return nil
+}
+
+// attempt makes one request and reports whether a failure may be retried.
+func (c *httpClient) attempt(ctx context.Context, w *Webhook, body []byte, contentType string) (time.Duration, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "NetVista")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 300 {
+		return 0, false, nil
+	}
+	err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(snippet))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryAfter(resp.Header.Get("Retry-After")), retry, err
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(v string) time.Duration {
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
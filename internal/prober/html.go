@@ -0,0 +1,182 @@
+package prober
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/pkg/models"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// maxExtracted bounds each extracted list (links, scripts, ...) per page.
+const maxExtracted = 500
+
+// document holds the fields extracted from an HTML page.
+type document struct {
+	Title       string
+	Description string
+	Generator   string
+	Canonical   string
+	Forms       []models.Form
+	Scripts     []string
+	Links       []string
+	Iframes     []string
+}
+
+// decodeBody converts body to UTF-8 using the charset from the Content-Type
+// header, a BOM or a <meta> declaration, falling back to the raw bytes.
+func decodeBody(body []byte, contentType string) string {
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return string(body)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
+
+// isHTML reports whether a response should be parsed as HTML.
+func isHTML(contentType string, body string) bool {
+	ct := strings.ToLower(contentType)
+	if strings.Contains(ct, "html") {
+		return true
+	}
+	if ct != "" && !strings.HasPrefix(ct, "text/plain") {
+		return false
+	}
+	head := strings.ToLower(strings.TrimSpace(body))
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	return strings.Contains(head, "<html") || strings.Contains(head, "<!doctype html") || strings.Contains(head, "<head")
+}
+
+// parseHTML extracts page metadata from a UTF-8 HTML document. Relative URLs
+// are resolved against base.
+func parseHTML(body string, base *url.URL) document {
+	var doc document
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return doc
+	}
+
+	resolve := func(ref string) string {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || base == nil {
+			return ref
+		}
+		u, err := base.Parse(ref)
+		if err != nil {
+			return ref
+		}
+		return u.String()
+	}
+	add := func(list *[]string, ref string) {
+		if ref = resolve(ref); ref != "" && len(*list) < maxExtracted {
+			*list = append(*list, ref)
+		}
+	}
+
+	currentForm := -1 // Index into doc.Forms while walking a form's children
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "title":
+				if doc.Title == "" && n.Namespace == "" { // Skip <svg><title>
+					doc.Title = collapseSpace(textContent(n))
+				}
+			case "meta":
+				name := strings.ToLower(attr(n, "name"))
+				if name == "" {
+					name = strings.ToLower(attr(n, "property"))
+				}
+				switch name {
+				case "description", "og:description":
+					if doc.Description == "" {
+						doc.Description = collapseSpace(attr(n, "content"))
+					}
+				case "generator":
+					doc.Generator = collapseSpace(attr(n, "content"))
+				}
+			case "link":
+				rel := strings.ToLower(attr(n, "rel"))
+				if rel == "canonical" && doc.Canonical == "" {
+					doc.Canonical = resolve(attr(n, "href"))
+				}
+			case "a":
+				add(&doc.Links, attr(n, "href"))
+			case "script":
+				add(&doc.Scripts, attr(n, "src"))
+			case "iframe", "frame":
+				add(&doc.Iframes, attr(n, "src"))
+			case "form":
+				if len(doc.Forms) < maxExtracted {
+					method := strings.ToUpper(attr(n, "method"))
+					if method == "" {
+						method = "GET"
+					}
+					doc.Forms = append(doc.Forms, models.Form{
+						Action: resolve(attr(n, "action")),
+						Method: method,
+					})
+					currentForm = len(doc.Forms) - 1
+					for c := n.FirstChild; c != nil; c = c.NextSibling {
+						walk(c)
+					}
+					currentForm = -1
+					return
+				}
+			case "input", "select", "textarea":
+				if currentForm >= 0 {
+					form := &doc.Forms[currentForm]
+					if strings.ToLower(attr(n, "type")) == "password" {
+						form.HasPassword = true
+					}
+					if name := attr(n, "name"); name != "" && len(form.Inputs) < maxExtracted {
+						form.Inputs = append(form.Inputs, name)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	return doc
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
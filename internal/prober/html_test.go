@@ -0,0 +1,86 @@
+package prober
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseHTML(t *testing.T) {
+	body := `<!DOCTYPE html>
+<html><head>
+<title>
+    Admin &amp; Console
+</title>
+<meta name="description" content="Internal  tools">
+<meta name="generator" content="WordPress 6.4">
+<link rel="canonical" href="/home">
+<script src="/static/app.js"></script>
+<script>var inline = 1;</script>
+</head>
+<body>
+<svg><title>icon</title></svg>
+<a href="https://other.example/x">x</a><a href="docs/">docs</a>
+<iframe src="//cdn.example/frame"></iframe>
+<form action="/login" method="post">
+  <input name="user"><input type="password" name="pass">
+</form>
+<form><input name="q"></form>
+</body></html>`
+
+	base, _ := url.Parse("https://example.com/app/")
+	doc := parseHTML(body, base)
+
+	if doc.Title != "Admin & Console" {
+		t.Errorf("Title = %q", doc.Title)
+	}
+	if doc.Description != "Internal tools" || doc.Generator != "WordPress 6.4" {
+		t.Errorf("meta = %q / %q", doc.Description, doc.Generator)
+	}
+	if doc.Canonical != "https://example.com/home" {
+		t.Errorf("Canonical = %q", doc.Canonical)
+	}
+	if len(doc.Scripts) != 1 || doc.Scripts[0] != "https://example.com/static/app.js" {
+		t.Errorf("Scripts = %v", doc.Scripts)
+	}
+	if len(doc.Links) != 2 || doc.Links[1] != "https://example.com/app/docs/" {
+		t.Errorf("Links = %v", doc.Links)
+	}
+	if len(doc.Iframes) != 1 || doc.Iframes[0] != "https://cdn.example/frame" {
+		t.Errorf("Iframes = %v", doc.Iframes)
+	}
+	if len(doc.Forms) != 2 {
+		t.Fatalf("Forms = %+v", doc.Forms)
+	}
+	login := doc.Forms[0]
+	if !login.HasPassword || login.Method != "POST" || login.Action != "https://example.com/login" || len(login.Inputs) != 2 {
+		t.Errorf("login form = %+v", login)
+	}
+	if doc.Forms[1].HasPassword || doc.Forms[1].Method != "GET" {
+		t.Errorf("search form = %+v", doc.Forms[1])
+	}
+}
+
+func TestDecodeBodyCharset(t *testing.T) {
+	// "Café" in windows-1252, declared only via <meta charset>.
+	raw := []byte("<html><head><meta charset=\"windows-1252\"><title>Caf\xe9</title></head></html>")
+	body := decodeBody(raw, "text/html")
+	doc := parseHTML(body, nil)
+	if doc.Title != "Café" {
+		t.Errorf("Title = %q; want Café", doc.Title)
+	}
+
+	// Header charset wins over the meta declaration.
+	raw = []byte("<title>\xfcber</title>")
+	if got := parseHTML(decodeBody(raw, "text/html; charset=iso-8859-1"), nil).Title; got != "über" {
+		t.Errorf("Title = %q; want über", got)
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	if !isHTML("", "<!DOCTYPE html><html>") {
+		t.Error("sniffed HTML not detected")
+	}
+	if isHTML("application/json", `{"html": "<html>"}`) {
+		t.Error("JSON treated as HTML")
+	}
+}
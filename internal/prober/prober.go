@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 	"time"
 
@@ -28,6 +28,7 @@ type Prober struct {
 	ProxyURL      string
 	CustomHeaders map[string]string
 	Redirects     int
+	Logger        *slog.Logger
 }
 
 func NewProber(timeout time.Duration, proxyURL string, customHeaders map[string]string, redirects int) *Prober {
@@ -54,6 +55,7 @@ func NewProber(timeout time.Duration, proxyURL string, customHeaders map[string]
 		ProxyURL:      proxyURL,
 		CustomHeaders: customHeaders,
 		Redirects:     redirects,
+		Logger:        slog.Default(),
 	}
 }
 
@@ -98,32 +100,40 @@ func (p *Prober) probeURL(ctx context.Context, targetURL string) (*models.Target
 	bodyBytes, err := io.ReadAll(limitedReader)
 	if err != nil {
 		// Log error but continue, as we might still have headers/status
-		fmt.Printf("Error reading response body for %s: %v\n", targetURL, err)
+		p.Logger.Warn("Error reading response body", "url", targetURL, "error", err)
 	}
-	body := string(bodyBytes)
+	contentType := resp.Header.Get("Content-Type")
+	body := decodeBody(bodyBytes, contentType)
 
-	// Simple title extraction
-	titleRegex := regexp.MustCompile("(?i)<title>(.*?)</title>")
-
-	title := "No Title"
-	matches := titleRegex.FindStringSubmatch(body)
-	if len(matches) > 1 {
-		title = matches[1]
+	var doc document
+	if isHTML(contentType, body) {
+		doc = parseHTML(body, resp.Request.URL)
+	}
+	if doc.Title == "" {
+		doc.Title = "No Title"
 	}
 
 	metadata := models.ResponseMetadata{
-		Title:      title,
-		StatusCode: resp.StatusCode,
-		Headers:    make(map[string]string),
-		Body:       body,
-		Timestamp:  time.Now(),
+		Title:       doc.Title,
+		StatusCode:  resp.StatusCode,
+		Headers:     make(map[string]string),
+		Body:        body,
+		Description: doc.Description,
+		Generator:   doc.Generator,
+		Canonical:   doc.Canonical,
+		Forms:       doc.Forms,
+		Scripts:     doc.Scripts,
+		Links:       doc.Links,
+		Iframes:     doc.Iframes,
+		Timestamp:   time.Now(),
 	}
 
 	for k, v := range resp.Header {
 		metadata.Headers[k] = strings.Join(v, ", ")
 	}
-
-	// Simple title extraction could go here, but we'll likely do it better with Playwright or a dedicated parser
+	// Set-Cookie values cannot be split back apart once joined (Expires
+	// contains a comma), so keep them individually as well.
+	metadata.Cookies = resp.Header.Values("Set-Cookie")
 
 	target := &models.Target{
 		URL:      targetURL,
@@ -0,0 +1,311 @@
+// Package progress turns scan events into live progress: a redrawn status
+// block on terminals and periodic log lines everywhere else.
+package progress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// Redraw interval of the terminal display.
+const redrawInterval = 200 * time.Millisecond
+
+// maxErrors is how many distinct errors a snapshot lists.
+const maxErrors = 3
+
+// Snapshot is the state of a scan at one point in time.
+type Snapshot struct {
+	Queued   int // Targets not yet picked up by a worker
+	InFlight int
+	Done     int
+	Failed   int
+	Total    int
+	Probed   int // Targets that answered a probe
+	Rendered int // Targets with a screenshot
+	Analyzed int
+	Elapsed  time.Duration
+	Rate     float64       // Finished targets per second
+	ETA      time.Duration // Zero until the rate is known
+	Errors   []ErrorCount  // Most frequent failures first
+}
+
+// ErrorCount is how often one kind of failure occurred.
+type ErrorCount struct {
+	Message string
+	Count   int
+}
+
+// Tracker aggregates scan events. It is safe for concurrent use, so its
+// Handle method can be registered with ScannerService.OnEvent directly.
+type Tracker struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	start    time.Time
+	total    int
+	started  int
+	done     int
+	failed   int
+	probed   int
+	rendered int
+	analyzed int
+	errors   map[string]int
+}
+
+// NewTracker creates an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{now: time.Now, errors: make(map[string]int)}
+}
+
+// Handle records one scan event.
+func (t *Tracker) Handle(e domain.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch e.Type {
+	case domain.EventTargetsQueued:
+		if t.start.IsZero() {
+			t.start = t.now()
+		}
+		t.total += e.Total
+	case domain.EventTargetStarted:
+		t.started++
+	case domain.EventTargetProbed:
+		if e.Err != nil {
+			t.errors[errorKind(e.Err.Error())]++
+		} else {
+			t.probed++
+		}
+	case domain.EventTargetRendered:
+		if e.Err != nil {
+			t.errors["render: "+errorKind(e.Err.Error())]++
+		} else {
+			t.rendered++
+		}
+	case domain.EventTargetAnalyzed:
+		t.analyzed++
+	case domain.EventTargetFinished:
+		t.done++
+		if e.Result != nil && e.Result.Error != "" {
+			t.failed++
+		}
+	}
+}
+
+// errorKind reduces an error to its cause so that failures of different
+// hosts group together: the text after the last colon, which drops URLs
+// and addresses from messages like `Get "https://x": dial tcp 1.2.3.4:443:
+// connect: connection refused`.
+func errorKind(msg string) string {
+	if i := strings.LastIndex(msg, ": "); i >= 0 {
+		msg = msg[i+2:]
+	}
+	msg = strings.TrimSpace(msg)
+	if len(msg) > 60 {
+		msg = msg[:57] + "..."
+	}
+	return msg
+}
+
+// Snapshot returns the current progress.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := Snapshot{
+		Queued:   t.total - t.started,
+		InFlight: t.started - t.done,
+		Done:     t.done,
+		Failed:   t.failed,
+		Total:    t.total,
+		Probed:   t.probed,
+		Rendered: t.rendered,
+		Analyzed: t.analyzed,
+	}
+	if !t.start.IsZero() {
+		s.Elapsed = t.now().Sub(t.start)
+	}
+	if s.Elapsed > 0 && t.done > 0 {
+		s.Rate = float64(t.done) / s.Elapsed.Seconds()
+		s.ETA = time.Duration(float64(t.total-t.done) / s.Rate * float64(time.Second))
+	}
+	for msg, n := range t.errors {
+		s.Errors = append(s.Errors, ErrorCount{Message: msg, Count: n})
+	}
+	sort.Slice(s.Errors, func(i, j int) bool {
+		if s.Errors[i].Count != s.Errors[j].Count {
+			return s.Errors[i].Count > s.Errors[j].Count
+		}
+		return s.Errors[i].Message < s.Errors[j].Message
+	})
+	if len(s.Errors) > maxErrors {
+		s.Errors = s.Errors[:maxErrors]
+	}
+	return s
+}
+
+// Lines formats a snapshot for the terminal display.
+func (s Snapshot) Lines() []string {
+	status := fmt.Sprintf(" [▶] %d/%d done · %d in flight · %d queued · %d failed · %.1f/s",
+		s.Done, s.Total, s.InFlight, s.Queued, s.Failed, s.Rate)
+	if s.ETA > 0 {
+		status += " · ETA " + s.ETA.Round(time.Second).String()
+	}
+	lines := []string{
+		status,
+		fmt.Sprintf("     probed %d · rendered %d · analyzed %d · elapsed %s",
+			s.Probed, s.Rendered, s.Analyzed, s.Elapsed.Round(time.Second)),
+	}
+	if len(s.Errors) > 0 {
+		var parts []string
+		for _, e := range s.Errors {
+			parts = append(parts, fmt.Sprintf("%s ×%d", e.Message, e.Count))
+		}
+		lines = append(lines, "     errors: "+strings.Join(parts, " · "))
+	}
+	return lines
+}
+
+// Attrs formats a snapshot as log attributes.
+func (s Snapshot) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int("done", s.Done),
+		slog.Int("total", s.Total),
+		slog.Int("in_flight", s.InFlight),
+		slog.Int("queued", s.Queued),
+		slog.Int("failed", s.Failed),
+		slog.Int("probed", s.Probed),
+		slog.Int("rendered", s.Rendered),
+		slog.Int("analyzed", s.Analyzed),
+		slog.String("rate", fmt.Sprintf("%.1f/s", s.Rate)),
+	}
+	if s.ETA > 0 {
+		attrs = append(attrs, slog.Duration("eta", s.ETA.Round(time.Second)))
+	}
+	for i, e := range s.Errors {
+		attrs = append(attrs, slog.String(fmt.Sprintf("error_%d", i+1), fmt.Sprintf("%s (%d)", e.Message, e.Count)))
+	}
+	return attrs
+}
+
+// Display shows a tracker's progress until stopped. On a terminal it keeps
+// a status block at the bottom of the output and redraws it; log output
+// written through the Display appears above the block. Otherwise it logs a
+// progress line every interval.
+type Display struct {
+	tracker  *Tracker
+	out      io.Writer
+	tty      bool
+	logger   *slog.Logger
+	interval time.Duration
+
+	mu    sync.Mutex
+	drawn int // Lines of the status block on screen
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewDisplay creates a display writing to out. With tty false, progress is
+// logged to logger every interval instead of drawn.
+func NewDisplay(tracker *Tracker, out io.Writer, tty bool, logger *slog.Logger, interval time.Duration) *Display {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Display{tracker: tracker, out: out, tty: tty, logger: logger, interval: interval}
+}
+
+// Start begins drawing or logging in the background.
+func (d *Display) Start() {
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	every := d.interval
+	if d.tty {
+		every = redrawInterval
+	}
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				d.update()
+			}
+		}
+	}()
+}
+
+// Stop ends the display, leaving the final progress on screen or in the
+// log.
+func (d *Display) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	<-d.done
+	d.stop = nil
+	d.update()
+}
+
+func (d *Display) update() {
+	snap := d.tracker.Snapshot()
+	if !d.tty {
+		if snap.Total > 0 && d.logger != nil {
+			d.logger.LogAttrs(context.Background(), slog.LevelInfo, "Scan progress", snap.Attrs()...)
+		}
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var buf bytes.Buffer
+	d.clear(&buf)
+	d.draw(&buf, snap.Lines())
+	d.out.Write(buf.Bytes())
+}
+
+// clear moves the cursor to the start of the status block and erases it;
+// callers hold d.mu.
+func (d *Display) clear(buf *bytes.Buffer) {
+	if d.drawn > 0 {
+		fmt.Fprintf(buf, "\x1b[%dA\r\x1b[J", d.drawn)
+		d.drawn = 0
+	}
+}
+
+// draw writes the status block; callers hold d.mu.
+func (d *Display) draw(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	d.drawn = len(lines)
+}
+
+// Write prints p above the status block, so a log handler can share the
+// terminal with the display.
+func (d *Display) Write(p []byte) (int, error) {
+	if !d.tty {
+		return d.out.Write(p)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var buf bytes.Buffer
+	redraw := d.drawn > 0
+	d.clear(&buf)
+	buf.Write(p)
+	if redraw {
+		d.draw(&buf, d.tracker.Snapshot().Lines())
+	}
+	if _, err := d.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feed scans n targets; every third fails to connect and the rest render.
+func feed(t *Tracker, n int) {
+	t.Handle(domain.Event{Type: domain.EventTargetsQueued, Total: 10})
+	for i := 0; i < n; i++ {
+		target := domain.Target{URL: "http://example.com"}
+		t.Handle(domain.Event{Type: domain.EventTargetStarted, Target: target})
+		res := &domain.ScanResult{Target: target}
+		if i%3 == 0 {
+			err := errors.New(`Get "http://10.0.0.1": dial tcp 10.0.0.1:80: connect: connection refused`)
+			t.Handle(domain.Event{Type: domain.EventTargetProbed, Target: target, Err: err})
+			res.Error = "probe failed after retries: " + err.Error()
+		} else {
+			t.Handle(domain.Event{Type: domain.EventTargetProbed, Target: target})
+			t.Handle(domain.Event{Type: domain.EventTargetRendered, Target: target})
+		}
+		t.Handle(domain.Event{Type: domain.EventTargetAnalyzed, Target: target, Result: res})
+		t.Handle(domain.Event{Type: domain.EventTargetFinished, Target: target, Result: res})
+	}
+	t.Handle(domain.Event{Type: domain.EventTargetStarted})
+}
+
+func TestTrackerSnapshot(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Unix(0, 0)
+	tracker.now = func() time.Time { return now }
+	feed(tracker, 4)
+	tracker.Handle(domain.Event{Type: domain.EventTargetRendered, Err: errors.New("page.goto: Timeout 30000ms exceeded")})
+	now = now.Add(2 * time.Second)
+
+	snap := tracker.Snapshot()
+	assert.Equal(t, 10, snap.Total)
+	assert.Equal(t, 4, snap.Done)
+	assert.Equal(t, 1, snap.InFlight)
+	assert.Equal(t, 5, snap.Queued)
+	assert.Equal(t, 2, snap.Failed)
+	assert.Equal(t, 2, snap.Probed)
+	assert.Equal(t, 2, snap.Rendered)
+	assert.Equal(t, 4, snap.Analyzed)
+	assert.Equal(t, 2.0, snap.Rate)
+	assert.Equal(t, 3*time.Second, snap.ETA)
+	assert.Equal(t, []ErrorCount{{"connection refused", 2}, {"render: Timeout 30000ms exceeded", 1}}, snap.Errors)
+
+	lines := snap.Lines()
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "4/10 done · 1 in flight · 5 queued · 2 failed · 2.0/s · ETA 3s")
+	assert.Contains(t, lines[2], "connection refused ×2")
+}
+
+func TestDisplayLogsWithoutTerminal(t *testing.T) {
+	var logs bytes.Buffer
+	tracker := NewTracker()
+	feed(tracker, 3)
+	d := NewDisplay(tracker, &logs, false, slog.New(slog.NewTextHandler(&logs, nil)), time.Hour)
+	d.Start()
+	d.Stop()
+
+	assert.Contains(t, logs.String(), `msg="Scan progress" done=3 total=10`)
+	assert.Contains(t, logs.String(), `error_1="connection refused (1)"`)
+	assert.NotContains(t, logs.String(), "\x1b[", "no terminal control codes in logs")
+}
+
+func TestDisplayKeepsLogsAboveStatus(t *testing.T) {
+	var out bytes.Buffer
+	tracker := NewTracker()
+	feed(tracker, 1)
+	d := NewDisplay(tracker, &out, true, nil, 0)
+	d.update()
+	d.Write([]byte("level=WARN msg=retrying\n"))
+
+	text := out.String()
+	assert.Equal(t, 2, strings.Count(text, "done ·"), "the block is redrawn after the log line")
+	assert.Contains(t, text, "\x1b[3A\r\x1b[Jlevel=WARN msg=retrying\n [▶] 1/10 done")
+}
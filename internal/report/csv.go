@@ -21,7 +21,7 @@ func ExportCSV(results []models.Target, filename string) error {
 	defer writer.Flush()
 
 	// Header
-	header := []string{"URL", "Status", "Title", "Technology", "PHash", "Timestamp"}
+	header := []string{"URL", "Status", "Title", "Class", "Technology", "PHash", "Screenshot", "Grade", "Score", "Findings", "Endpoints", "Paths", "Timestamp"}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
@@ -31,14 +31,58 @@ func ExportCSV(results []models.Target, filename string) error {
 			r.URL,
 			strconv.Itoa(r.Metadata.StatusCode),
 			r.Metadata.Title,
+			r.PageClass,
 			strings.Join(r.Metadata.Technology, ", "),
 			r.PHash,
+			r.Screenshot,
+			r.SecurityGrade,
+			strconv.Itoa(r.SecurityScore),
+			FindingSummary(r.Findings),
+			strconv.Itoa(len(r.Endpoints)),
+			pathSummary(r.Paths),
 			r.Metadata.Timestamp.String(),
 		}
-		if err := writer.Write(row); err != nil {
+		if err := writer.Write(CSVRow(row)); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// CSVRow escapes every cell of row with CSVCell.
+func CSVRow(row []string) []string {
+	for i, cell := range row {
+		row[i] = CSVCell(cell)
+	}
+	return row
+}
+
+// CSVCell keeps a cell from being read as a formula by spreadsheet
+// applications. Titles, tags and notes come from scanned hosts or other
+// users, so a cell starting with =, +, -, @, a tab or a carriage return is
+// prefixed with a single quote.
+func CSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// FindingSummary renders findings as "severity:id" pairs for a single cell.
+func FindingSummary(findings []models.Finding) string {
+	parts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		parts = append(parts, f.Severity+":"+f.ID)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// pathSummary renders path probe hits as "status path" pairs for a single cell.
+func pathSummary(hits []models.PathHit) string {
+	parts := make([]string, 0, len(hits))
+	for _, h := range hits {
+		parts = append(parts, strconv.Itoa(h.StatusCode)+" "+h.Path)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+// DefaultPageSize is the number of results per data chunk.
+const DefaultPageSize = 500
+
+const (
+	chunkDir      = "data"          // Chunk files, relative to report.html
+	chunkCallback = "netvistaChunk" // Global function each chunk file calls
+)
+
+// DashboardData is the JSON document the dashboard renders from. It is
+// embedded in report.html as <script type="application/json">, so scanned
+// content never reaches the page as markup or script.
+//
+// Large reports keep Results empty and list Chunks instead, which the
+// dashboard loads in the background. When served, API is set instead and
+// the dashboard queries the server.
+type DashboardData struct {
+	Total    int                `json:"total"`
+	Clusters []DashboardCluster `json:"clusters"`
+	Results  []DashboardResult  `json:"results"` // In cluster order, representative first
+	PageSize int                `json:"page_size,omitempty"`
+	Chunks   []string           `json:"chunks,omitempty"` // Chunk file paths, relative to report.html
+	API      string             `json:"api,omitempty"`    // Base URL of the serve API
+}
+
+// DashboardCluster summarises one visual cluster.
+type DashboardCluster struct {
+	ID          string  `json:"id"`
+	Label       string  `json:"label"`
+	Size        int     `json:"size"`
+	Diversity   float64 `json:"diversity"`
+	MaxDistance uint64  `json:"max_distance"`
+	PHash       string  `json:"phash,omitempty"`
+}
+
+// DashboardResult is the per-card view of a scanned target.
+type DashboardResult struct {
+	ID          int               `json:"id"` // Position in DashboardData.Results
+	URL         string            `json:"url"`
+	Title       string            `json:"title"`
+	Status      int               `json:"status"`
+	Alive       bool              `json:"alive"`
+	Error       string            `json:"error,omitempty"`
+	Tech        []string          `json:"tech,omitempty"`
+	PageClass   string            `json:"class,omitempty"`
+	Grade       string            `json:"grade,omitempty"`
+	Score       int               `json:"score,omitempty"`
+	Findings    []models.Finding  `json:"findings,omitempty"`
+	Endpoints   []models.Endpoint `json:"endpoints,omitempty"`
+	Paths       []models.PathHit  `json:"paths,omitempty"`
+	CNAMEs      []string          `json:"cnames,omitempty"`
+	PHash       string            `json:"phash,omitempty"`
+	Image       string            `json:"image,omitempty"` // Card image: thumbnail, screenshot or data URI
+	Full        string            `json:"full,omitempty"`  // Lightbox image
+	Cluster     int               `json:"cluster"`         // Index into DashboardData.Clusters
+	Rep         bool              `json:"rep,omitempty"`
+	ConsentRule string            `json:"consent,omitempty"`
+	Timestamp   time.Time         `json:"time"`
+
+	// Triage, filled in by serve
+	Tags   []string `json:"tags,omitempty"`
+	Notes  string   `json:"notes,omitempty"`
+	Review string   `json:"review,omitempty"`
+}
+
+// imageFunc returns the card and lightbox image sources for a target.
+type imageFunc func(t models.Target) (card, full string)
+
+// BuildDashboardData flattens clusters into the dashboard document.
+func BuildDashboardData(clusters []ClusterGroup, images imageFunc) DashboardData {
+	data := DashboardData{Clusters: make([]DashboardCluster, 0, len(clusters))}
+	for ci, c := range clusters {
+		data.Clusters = append(data.Clusters, DashboardCluster{
+			ID:          c.ID,
+			Label:       c.Label,
+			Size:        c.Size,
+			Diversity:   c.Diversity,
+			MaxDistance: c.MaxDistance,
+			PHash:       c.Representative.PHash,
+		})
+		for _, m := range c.Members {
+			card, full := images(m)
+			data.Results = append(data.Results, DashboardResult{
+				ID:          len(data.Results),
+				URL:         m.URL,
+				Title:       m.Metadata.Title,
+				Status:      m.Metadata.StatusCode,
+				Alive:       m.IsAlive,
+				Error:       m.Error,
+				Tech:        m.Metadata.Technology,
+				PageClass:   m.PageClass,
+				Grade:       m.SecurityGrade,
+				Score:       m.SecurityScore,
+				Findings:    m.Findings,
+				Endpoints:   m.Endpoints,
+				Paths:       m.Paths,
+				CNAMEs:      m.CNAMEs,
+				PHash:       m.PHash,
+				Image:       card,
+				Full:        full,
+				Cluster:     ci,
+				Rep:         m.URL == c.ID,
+				ConsentRule: m.ConsentRule,
+				Timestamp:   m.Metadata.Timestamp,
+			})
+		}
+	}
+	data.Total = len(data.Results)
+	return data
+}
+
+// WriteChunks moves the results of data into files of pageSize results under
+// outDir/data. Each file is a script passing one page to netvistaChunk, so
+// the report still loads from file:// where fetching JSON is blocked. The
+// payload is plain JSON as produced by encoding/json, which escapes <, > and
+// &, so scanned content cannot close the script.
+func WriteChunks(data *DashboardData, outDir string, pageSize int) error {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	dir := filepath.Join(outDir, chunkDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	stale, _ := filepath.Glob(filepath.Join(dir, "results-*.js"))
+	for _, path := range stale {
+		os.Remove(path)
+	}
+
+	data.PageSize = pageSize
+	data.Chunks = nil
+	for page := 0; page*pageSize < len(data.Results); page++ {
+		end := (page + 1) * pageSize
+		if end > len(data.Results) {
+			end = len(data.Results)
+		}
+		payload, err := json.Marshal(data.Results[page*pageSize : end])
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("results-%04d.js", page+1)
+		content := fmt.Sprintf("%s(%d, %s);\n", chunkCallback, page, payload)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			return err
+		}
+		data.Chunks = append(data.Chunks, chunkDir+"/"+name)
+	}
+	data.Results = []DashboardResult{}
+	return nil
+}
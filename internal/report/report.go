@@ -1,130 +1,266 @@
 package report
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"html/template"
 	"io"
 	"io/fs"
-	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"time"
 
-	"github.com/ismailtsdln/netvista/internal/screenshot"
 	"github.com/ismailtsdln/netvista/pkg/models"
 	"github.com/ismailtsdln/netvista/web"
 )
 
 type ReportData struct {
-	Results []models.Target
-	Grouped map[string][]models.Target
+	Results  []models.Target
+	Clusters []ClusterGroup
+
+	// Data is rendered by the dashboard script
+	Data DashboardData
+
+	// Assets; inlined in single-file mode
+	Inline bool
+	CSS    template.CSS
+	JS     template.JS
+	Logo   interface{} // Relative path, or a data URI (template.URL) when inlined
 }
 
-func GenerateHTML(results []models.Target, templatePath string, outputPath string) error {
-	// Ensure assets directory exists in output
-	outDir := filepath.Dir(outputPath)
-	assetsDir := filepath.Join(outDir, "assets")
-	os.MkdirAll(assetsDir, 0755)
+// ClusterGroup is a visual cluster as presented in the HTML report.
+type ClusterGroup struct {
+	ID             string
+	Label          string
+	Representative models.Target
+	Members        []models.Target // Representative first, then by distance
+	Size           int
+	Diversity      float64 // Mean distance of members to the representative
+	MaxDistance    uint64
+}
 
-	// Copy logo
-	logoDst := filepath.Join(assetsDir, "logo.png")
+// BuildClusters groups results by the cluster assigned during the scan,
+// ordered from largest to smallest.
+func BuildClusters(results []models.Target) []ClusterGroup {
+	index := make(map[string]int)
+	var clusters []ClusterGroup
+	for _, r := range results {
+		key := r.GroupID
+		if key == "" {
+			key = r.URL
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(clusters)
+			index[key] = i
+			clusters = append(clusters, ClusterGroup{ID: key})
+		}
+		clusters[i].Members = append(clusters[i].Members, r)
+	}
 
-	// Try to get logo from embedded first as it's more reliable for installed binaries
-	logoData, err := fs.ReadFile(web.AssetsFS, "assets/logo.png")
-	if err == nil {
-		os.WriteFile(logoDst, logoData, 0644)
-	} else {
-		// Fallback to local file
-		logoSrc := filepath.Join("web", "assets", "logo.png")
-		if _, err := os.Stat(logoSrc); err == nil {
-			s, err := os.Open(logoSrc)
-			if err == nil {
-				defer s.Close()
-				d, err := os.Create(logoDst)
-				if err == nil {
-					defer d.Close()
-					io.Copy(d, s)
-				}
+	for i := range clusters {
+		c := &clusters[i]
+		sort.SliceStable(c.Members, func(a, b int) bool {
+			ma, mb := c.Members[a], c.Members[b]
+			if (ma.URL == c.ID) != (mb.URL == c.ID) {
+				return ma.URL == c.ID
+			}
+			if ma.PHashScore != mb.PHashScore {
+				return ma.PHashScore < mb.PHashScore
+			}
+			return ma.URL < mb.URL
+		})
+
+		c.Representative = c.Members[0]
+		c.Size = len(c.Members)
+		c.Label = c.Representative.ClusterLabel
+		if c.Label == "" {
+			c.Label = c.ID
+		}
+
+		var total uint64
+		for _, m := range c.Members {
+			total += m.PHashScore
+			if m.PHashScore > c.MaxDistance {
+				c.MaxDistance = m.PHashScore
 			}
 		}
+		c.Diversity = float64(total) / float64(c.Size)
 	}
 
-	start := time.Now()
-	// ...
+	sort.SliceStable(clusters, func(a, b int) bool {
+		if clusters[a].Size != clusters[b].Size {
+			return clusters[a].Size > clusters[b].Size
+		}
+		return clusters[a].ID < clusters[b].ID
+	})
+	return clusters
+}
 
-	// Simple clustering
-	grouped := make(map[string][]models.Target)
-	processed := make(map[string]bool)
+// Defaults for embedding screenshots in single-file reports.
+const (
+	DefaultMaxInlineImage = 512 * 1024       // Per image
+	DefaultMaxInlineTotal = 64 * 1024 * 1024 // Per report
+)
 
-	for i, r1 := range results {
-		if processed[r1.URL] {
-			continue
-		}
+// dashboardAssets are copied next to report.html, or inlined into it.
+var dashboardAssets = []string{"logo.png", "dashboard.css", "dashboard.js"}
 
-		groupKey := r1.URL
-		grouped[groupKey] = append(grouped[groupKey], r1)
-		processed[r1.URL] = true
+// HTMLOptions controls how the HTML report is written.
+type HTMLOptions struct {
+	// SingleFile inlines CSS, JavaScript, the logo and thumbnails so the
+	// report works offline as a single file.
+	SingleFile     bool
+	MaxInlineImage int64 // Larger images are left out; 0 uses DefaultMaxInlineImage
+	MaxInlineTotal int64 // Budget for all images; 0 uses DefaultMaxInlineTotal
 
-		if r1.PHash == "" {
-			continue
-		}
+	// PageSize is the number of results per data chunk; 0 uses
+	// DefaultPageSize. Single-file reports keep all results inline.
+	PageSize int
+}
 
-		for j := i + 1; j < len(results); j++ {
-			r2 := results[j]
-			if processed[r2.URL] || r2.PHash == "" {
-				continue
-			}
+func GenerateHTML(results []models.Target, templatePath string, outputPath string, opts HTMLOptions) error {
+	outDir := filepath.Dir(outputPath)
+	data := ReportData{
+		Results:  results,
+		Clusters: BuildClusters(results),
+		Logo:     "assets/logo.png",
+	}
 
-			dist, err := screenshot.HammingDistance(r1.PHash, r2.PHash)
-			if err == nil && dist < 12 { // Increased threshold slightly for better grouping
-				grouped[groupKey] = append(grouped[groupKey], r2)
-				processed[r2.URL] = true
+	var images map[string]string
+	if opts.SingleFile {
+		css, err := ReadAsset("dashboard.css")
+		if err != nil {
+			return err
+		}
+		js, err := ReadAsset("dashboard.js")
+		if err != nil {
+			return err
+		}
+		data.Inline = true
+		data.CSS = template.CSS(css)
+		data.JS = template.JS(js)
+		if logo, err := ReadAsset("logo.png"); err == nil {
+			data.Logo = template.URL(dataURI("logo.png", logo))
+		}
+		images = inlineImages(results, outDir, opts)
+	} else {
+		// Ensure assets directory exists in output
+		assetsDir := filepath.Join(outDir, "assets")
+		os.MkdirAll(assetsDir, 0755)
+		for _, name := range dashboardAssets {
+			if content, err := ReadAsset(name); err == nil {
+				os.WriteFile(filepath.Join(assetsDir, name), content, 0644)
 			}
 		}
 	}
-	slog.Info("Clustering complete", "duration", time.Since(start), "groups", len(grouped))
 
-	var tmpl *template.Template
-	var terr error
+	// Cards show the thumbnail when there is one. In single-file mode only
+	// inlined images are available, and the lightbox reuses them since
+	// full-size screenshots are too large to inline.
+	data.Data = BuildDashboardData(data.Clusters, func(t models.Target) (string, string) {
+		card := t.Thumbnail
+		if card == "" {
+			card = t.Screenshot
+		}
+		if images != nil {
+			return images[card], images[card]
+		}
+		return card, t.Screenshot
+	})
+	if !opts.SingleFile {
+		if err := WriteChunks(&data.Data, outDir, opts.PageSize); err != nil {
+			return err
+		}
+	}
 
-	funcMap := template.FuncMap{
-		"sanitize": func(s string) string {
-			s = strings.Replace(s, "https://", "", 1)
-			s = strings.Replace(s, "http://", "", 1)
-			s = strings.ReplaceAll(s, "/", "_")
-			s = strings.ReplaceAll(s, ":", "_")
-			s = strings.ReplaceAll(s, ".", "_")
-			return strings.Trim(s, "_")
-		},
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
+
+	return WriteDashboard(f, templatePath, data)
+}
+
+// WriteDashboard renders the dashboard template. The template at
+// templatePath is used when it exists, otherwise the embedded copy.
+func WriteDashboard(w io.Writer, templatePath string, data ReportData) error {
+	var tmpl *template.Template
+	var terr error
 
 	// Try local template first
 	if _, err := os.Stat(templatePath); err == nil {
-		tmpl, terr = template.New(filepath.Base(templatePath)).Funcs(funcMap).ParseFiles(templatePath)
+		tmpl, terr = template.New(filepath.Base(templatePath)).ParseFiles(templatePath)
 	} else {
 		// Fallback to embedded
 		embeddedPath := filepath.Join("templates", filepath.Base(templatePath))
-		tmpl, terr = template.New(filepath.Base(templatePath)).Funcs(funcMap).ParseFS(web.AssetsFS, embeddedPath)
+		tmpl, terr = template.New(filepath.Base(templatePath)).ParseFS(web.AssetsFS, embeddedPath)
 	}
 
 	if terr != nil {
 		return terr
 	}
+	return tmpl.Execute(w, data)
+}
 
-	f, err := os.Create(outputPath)
+// ReadAsset returns a dashboard asset, preferring the embedded copy as it is
+// more reliable for installed binaries.
+func ReadAsset(name string) ([]byte, error) {
+	content, err := fs.ReadFile(web.AssetsFS, "assets/"+name)
+	if err == nil {
+		return content, nil
+	}
+	return os.ReadFile(filepath.Join("web", "assets", name))
+}
 
-	if err != nil {
-		return err
+// inlineImages reads the card image of each result (thumbnail, else
+// screenshot) and returns data URIs keyed by relative path, within the
+// configured size limits.
+func inlineImages(results []models.Target, outDir string, opts HTMLOptions) map[string]string {
+	maxImage, maxTotal := opts.MaxInlineImage, opts.MaxInlineTotal
+	if maxImage <= 0 {
+		maxImage = DefaultMaxInlineImage
+	}
+	if maxTotal <= 0 {
+		maxTotal = DefaultMaxInlineTotal
 	}
-	defer f.Close()
 
-	data := ReportData{
-		Results: results,
-		Grouped: grouped,
+	images := make(map[string]string)
+	var total int64
+	for _, r := range results {
+		rel := r.Thumbnail
+		if rel == "" {
+			rel = r.Screenshot
+		}
+		if rel == "" || images[rel] != "" {
+			continue
+		}
+		path := filepath.Join(outDir, filepath.FromSlash(rel))
+		info, err := os.Stat(path)
+		if err != nil || info.Size() > maxImage || total+info.Size() > maxTotal {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		total += int64(len(content))
+		images[rel] = dataURI(rel, content)
 	}
+	return images
+}
 
-	return tmpl.Execute(f, data)
+func dataURI(name string, content []byte) string {
+	mime := "image/png"
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		mime = "image/jpeg"
+	case ".webp":
+		mime = "image/webp"
+	}
+	return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(content)
 }
 
 func ExportJSON(results []models.Target, outputPath string) error {
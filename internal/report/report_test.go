@@ -0,0 +1,229 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+func TestGenerateHTMLSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "screenshots", "thumbs"), 0755)
+	thumb := []byte("\x89PNG\r\n\x1a\nfake-thumbnail")
+	os.WriteFile(filepath.Join(dir, "screenshots", "thumbs", "a.png"), thumb, 0644)
+	os.WriteFile(filepath.Join(dir, "screenshots", "big.png"), make([]byte, 2048), 0644)
+
+	results := []models.Target{
+		{URL: "https://a.example.com", Screenshot: "screenshots/a.png", Thumbnail: "screenshots/thumbs/a.png", Metadata: models.ResponseMetadata{StatusCode: 200, Title: "A"}},
+		{URL: "https://b.example.com", Screenshot: "screenshots/big.png", Metadata: models.ResponseMetadata{StatusCode: 200, Title: "B"}},
+	}
+
+	out := filepath.Join(dir, "report.html")
+	if err := GenerateHTML(results, "dashboard.html", out, HTMLOptions{SingleFile: true, MaxInlineImage: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(html)
+
+	if _, err := os.Stat(filepath.Join(dir, "assets")); err == nil {
+		t.Error("single-file mode should not write an assets directory")
+	}
+	if !strings.Contains(page, "data:image/png;base64,") {
+		t.Error("thumbnail not embedded")
+	}
+	data := reportData(t, page)
+	if len(data.Results) != 2 || !strings.HasPrefix(data.Results[0].Image, "data:image/png;base64,") || data.Results[1].Image != "" {
+		t.Error("oversized screenshot should fall back to the placeholder")
+	}
+	if !strings.Contains(page, "renderWindow") || !strings.Contains(page, "--card-bg") {
+		t.Error("dashboard JS/CSS not inlined")
+	}
+	external := regexp.MustCompile(`(?i)(src|href)="(https?:)?//|placehold\.co|unpkg\.com|assets/`)
+	for _, m := range external.FindAllString(page, -1) {
+		if !strings.HasPrefix(m, `href="https://`) { // Links to the scanned hosts are fine
+			t.Errorf("report references external resource: %s", m)
+		}
+	}
+}
+
+func TestGenerateHTMLWritesAssets(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "report.html")
+	if err := GenerateHTML(nil, "dashboard.html", out, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range dashboardAssets {
+		if _, err := os.Stat(filepath.Join(dir, "assets", name)); err != nil {
+			t.Errorf("asset %s not written: %v", name, err)
+		}
+	}
+}
+
+func TestGenerateHTMLHostileContent(t *testing.T) {
+	hostile := []string{
+		`</script><script>alert(1)</script>`,
+		`"><img src=x onerror=alert(2)>`,
+		"<!-- <script> \u2028\u2029 ' \" & \u2028",
+		"</SCRIPT ><svg onload=alert(3)>",
+	}
+	var results []models.Target
+	for i, h := range hostile {
+		results = append(results, models.Target{
+			URL:       "https://" + string(rune('a'+i)) + ".example.com/?q=" + h,
+			Thumbnail: "javascript:alert(4)",
+			Metadata: models.ResponseMetadata{
+				StatusCode: 200,
+				Title:      h,
+				Technology: []string{h},
+				Headers:    map[string]string{"Server": h},
+			},
+			Findings: []models.Finding{{Title: h, Evidence: h, Severity: "high"}},
+		})
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "report.html")
+	if err := GenerateHTML(results, "dashboard.html", out, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(raw)
+
+	// Only the data blob and the dashboard script may appear.
+	if n := strings.Count(strings.ToLower(page), "<script"); n != 2 {
+		t.Errorf("page has %d script tags, want 2", n)
+	}
+	for _, marker := range []string{"alert(", "onerror", "onload", "<svg", "\u2028"} {
+		if strings.Contains(page, marker) && !strings.Contains(blob(t, page), marker) {
+			t.Errorf("%q appears outside the data blob", marker)
+		}
+	}
+
+	data := reportData(t, page)
+	data.Results = loadChunks(t, dir, data)
+	if len(data.Results) != len(hostile) {
+		t.Fatalf("got %d results, want %d", len(data.Results), len(hostile))
+	}
+	titles := make(map[string]bool)
+	for _, r := range data.Results {
+		titles[r.Title] = true
+		if len(r.Tech) != 1 || r.Tech[0] != r.Title || r.Findings[0].Evidence != r.Title {
+			t.Errorf("content of %q did not round-trip", r.Title)
+		}
+	}
+	for _, h := range hostile {
+		if !titles[h] {
+			t.Errorf("title %q did not round-trip", h)
+		}
+	}
+}
+
+func TestGenerateHTMLChunks(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "data"), 0755)
+	os.WriteFile(filepath.Join(dir, "data", "results-0009.js"), []byte("stale"), 0644)
+
+	var results []models.Target
+	for i := 0; i < 12; i++ {
+		r := models.Target{URL: fmt.Sprintf("https://h%02d.example.com", i), IsAlive: true}
+		if i%4 == 0 {
+			r.IsAlive = false
+			r.Error = "connection refused"
+		}
+		results = append(results, r)
+	}
+	out := filepath.Join(dir, "report.html")
+	if err := GenerateHTML(results, "dashboard.html", out, HTMLOptions{PageSize: 5}); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(out)
+	data := reportData(t, string(raw))
+
+	if len(data.Results) != 0 || data.Total != 12 || data.PageSize != 5 {
+		t.Errorf("index: total=%d page_size=%d inline=%d", data.Total, data.PageSize, len(data.Results))
+	}
+	if len(data.Chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(data.Chunks))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data", "results-0009.js")); err == nil {
+		t.Error("stale chunk not removed")
+	}
+
+	loaded := loadChunks(t, dir, data)
+	if len(loaded) != 12 {
+		t.Fatalf("chunks hold %d results, want 12", len(loaded))
+	}
+	failed := 0
+	for _, r := range loaded {
+		if r.Error != "" {
+			failed++
+			if r.Alive {
+				t.Errorf("%s has an error but is marked alive", r.URL)
+			}
+		}
+	}
+	if failed != 3 {
+		t.Errorf("got %d failed results, want 3", failed)
+	}
+}
+
+var blobPattern = regexp.MustCompile(`(?s)<script type="application/json" id="report-data">(.*?)</script>`)
+
+// blob returns the raw contents of the embedded data script.
+func blob(t *testing.T, page string) string {
+	t.Helper()
+	m := blobPattern.FindStringSubmatch(page)
+	if m == nil {
+		t.Fatal("report has no data blob")
+	}
+	return m[1]
+}
+
+// loadChunks decodes the chunk files listed in data, checking that they only
+// call the chunk callback.
+func loadChunks(t *testing.T, dir string, data DashboardData) []DashboardResult {
+	t.Helper()
+	var results []DashboardResult
+	for i, name := range data.Chunks {
+		raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix := fmt.Sprintf("%s(%d, ", chunkCallback, i)
+		content := string(raw)
+		if !strings.HasPrefix(content, prefix) || !strings.HasSuffix(content, ");\n") {
+			t.Fatalf("chunk %s has unexpected framing", name)
+		}
+		if strings.Contains(content, "<") {
+			t.Errorf("chunk %s contains raw markup", name)
+		}
+		var page []DashboardResult
+		if err := json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(content, prefix), ");\n")), &page); err != nil {
+			t.Fatalf("chunk %s is not valid JSON: %v", name, err)
+		}
+		results = append(results, page...)
+	}
+	return results
+}
+
+// reportData decodes the embedded data the way the dashboard does.
+func reportData(t *testing.T, page string) DashboardData {
+	t.Helper()
+	var data DashboardData
+	if err := json.Unmarshal([]byte(blob(t, page)), &data); err != nil {
+		t.Fatalf("data blob is not valid JSON: %v", err)
+	}
+	return data
+}
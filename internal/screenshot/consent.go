@@ -0,0 +1,84 @@
+package screenshot
+
+import (
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// dismissConsent applies the configured consent rules to every frame of the
+// page and returns the name of the rule that fired, or "" if none did.
+// Rules whose banner is detected but whose button cannot be clicked fall back
+// to hiding the banner with their CSS.
+func (c *Capturer) dismissConsent(page playwright.Page) string {
+	rules := c.ConsentRules
+	if rules == nil {
+		return ""
+	}
+
+	frames := page.Frames()
+	for _, rule := range rules.Rules {
+		detected := false
+		for _, frame := range frames {
+			if rule.Detect != "" {
+				if el, err := frame.QuerySelector(rule.Detect); err != nil || el == nil {
+					continue
+				}
+				detected = true
+			}
+
+			if clickAccept(frame, rule.Accept, rule.MatchText, rules.IsAcceptText) {
+				// Small wait for overlay to disappear
+				page.WaitForTimeout(500)
+				injectCSS(frame, rule.HideCSS)
+				return rule.Name
+			}
+		}
+
+		if detected && rule.HideCSS != "" {
+			for _, frame := range frames {
+				injectCSS(frame, rule.HideCSS)
+			}
+			return rule.Name + " (hidden)"
+		}
+	}
+
+	if rules.OverlayCSS != "" {
+		injectCSS(page.MainFrame(), rules.OverlayCSS)
+		return "Overlay CSS"
+	}
+	return ""
+}
+
+// clickAccept clicks the first visible element matching one of the selectors.
+// When matchText is set, the element's text must also be an accept text.
+func clickAccept(frame playwright.Frame, selectors []string, matchText bool, isAccept func(string) bool) bool {
+	for _, sel := range selectors {
+		elements, err := frame.QuerySelectorAll(sel)
+		if err != nil {
+			continue
+		}
+		for _, el := range elements {
+			if visible, _ := el.IsVisible(); !visible {
+				continue
+			}
+			if matchText {
+				text, _ := el.TextContent()
+				if !isAccept(strings.TrimSpace(text)) {
+					continue
+				}
+			}
+			if err := el.Click(); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func injectCSS(frame playwright.Frame, css string) {
+	if css == "" {
+		return
+	}
+	frame.AddStyleTag(playwright.FrameAddStyleTagOptions{Content: playwright.String(css)})
+}
@@ -0,0 +1,107 @@
+package screenshot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Framework is a client-side technology detected in the rendered page.
+type Framework struct {
+	Name    string
+	Version string
+}
+
+// frameworkDetectionScript runs inside the page and inspects runtime globals
+// and DOM markers left behind by common SPA frameworks and libraries. It
+// returns a list of {name, version} objects; version is empty when unknown.
+const frameworkDetectionScript = `() => {
+	const found = [];
+	const add = (name, version) => found.push({ name, version: version ? String(version) : "" });
+	const safe = (fn) => { try { return fn(); } catch (e) { return undefined; } };
+	const w = window;
+
+	// React: devtools hook renderers, global, or fiber/root markers on DOM nodes.
+	const reactVersion = safe(() => {
+		const hook = w.__REACT_DEVTOOLS_GLOBAL_HOOK__;
+		if (hook && hook.renderers && hook.renderers.size > 0) {
+			for (const r of hook.renderers.values()) {
+				if (r && r.version) return r.version;
+			}
+		}
+		return w.React && w.React.version;
+	});
+	const reactMarker = safe(() => {
+		if (document.querySelector("[data-reactroot], [data-reactid]")) return true;
+		const nodes = [document.body, ...document.querySelectorAll("body > *")];
+		return nodes.some((el) => el && (el._reactRootContainer ||
+			Object.keys(el).some((k) => k.startsWith("__reactFiber") || k.startsWith("__reactContainer"))));
+	});
+	if (reactVersion || reactMarker) add("React", reactVersion);
+
+	if (safe(() => w.__NEXT_DATA__ || w.next)) add("Next.js", safe(() => w.next && w.next.version));
+	if (safe(() => w.___gatsby)) add("Gatsby");
+	if (safe(() => w.__remixContext)) add("Remix");
+	if (safe(() => w.preact || w.__PREACT_DEVTOOLS__)) add("Preact");
+
+	// Vue 2 exposes __vue__ on the root element, Vue 3 exposes __vue_app__.
+	const vueVersion = safe(() => {
+		if (w.Vue && w.Vue.version) return w.Vue.version;
+		const v3 = document.querySelector("[data-v-app]") || document.getElementById("app");
+		if (v3 && v3.__vue_app__) return v3.__vue_app__.version;
+		for (const el of document.querySelectorAll("body *")) {
+			if (el.__vue_app__) return el.__vue_app__.version;
+			if (el.__vue__) return el.__vue__.$root.constructor.version;
+		}
+		return undefined;
+	});
+	if (vueVersion || safe(() => w.__VUE__ || document.querySelector("[data-v-app]"))) add("Vue.js", vueVersion);
+	if (safe(() => w.__NUXT__ || w.$nuxt || w.useNuxtApp)) add("Nuxt.js", safe(() => w.$nuxt && w.$nuxt.$root.constructor.version));
+
+	const ngVersion = safe(() => {
+		const el = document.querySelector("[ng-version]");
+		return el && el.getAttribute("ng-version");
+	});
+	if (ngVersion || safe(() => w.ng && w.getAllAngularRootElements)) add("Angular", ngVersion);
+	if (safe(() => w.angular && w.angular.version)) add("AngularJS", w.angular.version.full);
+
+	const svelteVersion = safe(() => w.__svelte && w.__svelte.v && Array.from(w.__svelte.v)[0]);
+	if (svelteVersion || safe(() => document.querySelector("[class*='svelte-']"))) add("Svelte", svelteVersion);
+
+	if (safe(() => w.Ember)) add("Ember.js", safe(() => w.Ember.VERSION));
+	if (safe(() => w.Backbone)) add("Backbone.js", safe(() => w.Backbone.VERSION));
+	if (safe(() => w.Alpine)) add("Alpine.js", safe(() => w.Alpine.version));
+	if (safe(() => w.jQuery && w.jQuery.fn)) add("jQuery", safe(() => w.jQuery.fn.jquery));
+
+	return found;
+}`
+
+// parseFrameworks converts the value returned by frameworkDetectionScript into
+// Framework entries, dropping malformed items and duplicates.
+func parseFrameworks(v interface{}) []Framework {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var frameworks []Framework
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		version := ""
+		if raw, ok := m["version"]; ok && raw != nil {
+			version = strings.TrimSpace(fmt.Sprint(raw))
+		}
+		frameworks = append(frameworks, Framework{Name: name, Version: version})
+	}
+	return frameworks
+}
@@ -0,0 +1,36 @@
+package screenshot
+
+import (
+	"testing"
+)
+
+func TestParseFrameworks(t *testing.T) {
+	raw := []interface{}{
+		map[string]interface{}{"name": "React", "version": "18.2.0"},
+		map[string]interface{}{"name": "Next.js", "version": ""},
+		map[string]interface{}{"name": "React", "version": "17.0.0"}, // duplicate
+		map[string]interface{}{"version": "1.0"},                     // missing name
+		"garbage",
+		map[string]interface{}{"name": "jQuery", "version": 3.7},
+	}
+
+	got := parseFrameworks(raw)
+	want := []Framework{
+		{Name: "React", Version: "18.2.0"},
+		{Name: "Next.js", Version: ""},
+		{Name: "jQuery", Version: "3.7"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parseFrameworks returned %d entries; want %d (%v)", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+
+	if fw := parseFrameworks(nil); fw != nil {
+		t.Errorf("parseFrameworks(nil) = %v; want nil", fw)
+	}
+}
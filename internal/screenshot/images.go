@@ -0,0 +1,138 @@
+package screenshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/nfnt/resize"
+	"github.com/playwright-community/playwright-go"
+)
+
+// Supported screenshot output formats.
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// ImageOptions controls how screenshots and thumbnails are written to disk.
+type ImageOptions struct {
+	Format           string // png, jpeg or webp
+	Quality          int    // 1-100, used by jpeg and webp
+	ThumbnailWidth   int    // 0 disables thumbnails
+	ContentAddressed bool   // Name files after the hash of their content
+}
+
+// Normalize validates the options and fills in defaults.
+func (o ImageOptions) Normalize() (ImageOptions, error) {
+	switch strings.ToLower(o.Format) {
+	case "", FormatPNG:
+		o.Format = FormatPNG
+	case FormatJPEG, "jpg":
+		o.Format = FormatJPEG
+	case FormatWebP:
+		o.Format = FormatWebP
+	default:
+		return o, fmt.Errorf("unsupported screenshot format %q (want png, jpeg or webp)", o.Format)
+	}
+	if o.Quality <= 0 || o.Quality > 100 {
+		o.Quality = 80
+	}
+	if o.ThumbnailWidth < 0 {
+		o.ThumbnailWidth = 0
+	}
+	return o, nil
+}
+
+// Ext returns the file extension for the configured format.
+func (o ImageOptions) Ext() string {
+	if o.Format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + o.Format
+}
+
+// contentName returns a stable file name derived from the image bytes.
+func contentName(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// makeThumbnail scales img down to the given width, preserving aspect ratio.
+func makeThumbnail(img image.Image, width int) image.Image {
+	if img.Bounds().Dx() <= width {
+		return img
+	}
+	return resize.Resize(uint(width), 0, img, resize.Lanczos3)
+}
+
+// encodeImage encodes img as png or jpeg. WebP has no encoder in the standard
+// library and is produced by the browser instead (see toWebP).
+func encodeImage(img image.Image, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeImage writes data to path. Content-addressed files that already exist
+// are identical by construction and are not rewritten.
+func writeImage(path string, data []byte, skipExisting bool) error {
+	if skipExisting {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// webpScript re-encodes an image data URL as WebP using a canvas.
+const webpScript = `async ([src, quality]) => {
+	const img = new Image();
+	img.src = src;
+	await img.decode();
+	const canvas = document.createElement("canvas");
+	canvas.width = img.naturalWidth;
+	canvas.height = img.naturalHeight;
+	canvas.getContext("2d").drawImage(img, 0, 0);
+	return canvas.toDataURL("image/webp", quality);
+}`
+
+// toWebP converts PNG bytes to WebP with the browser's encoder. It runs on a
+// blank page so the target site's CSP cannot interfere.
+func toWebP(bctx playwright.BrowserContext, pngData []byte, quality int) ([]byte, error) {
+	page, err := bctx.NewPage()
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+
+	src := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+	res, err := page.Evaluate(webpScript, []interface{}{src, float64(quality) / 100})
+	if err != nil {
+		return nil, fmt.Errorf("webp conversion failed: %w", err)
+	}
+
+	dataURL, _ := res.(string)
+	const prefix = "data:image/webp;base64,"
+	if !strings.HasPrefix(dataURL, prefix) {
+		return nil, fmt.Errorf("browser does not support webp encoding")
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURL, prefix))
+}
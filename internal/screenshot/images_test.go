@@ -0,0 +1,47 @@
+package screenshot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestImageOptionsNormalize(t *testing.T) {
+	opts, err := ImageOptions{Format: "JPG", Quality: 0}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize returned error: %v", err)
+	}
+	if opts.Format != FormatJPEG || opts.Ext() != ".jpg" || opts.Quality != 80 {
+		t.Errorf("unexpected normalized options: %+v (ext %s)", opts, opts.Ext())
+	}
+
+	if _, err := (ImageOptions{Format: "gif"}).Normalize(); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestThumbnailAndContentName(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1280, 720))
+	img.Set(10, 10, color.White)
+
+	thumb := makeThumbnail(img, 320)
+	if w, h := thumb.Bounds().Dx(), thumb.Bounds().Dy(); w != 320 || h != 180 {
+		t.Errorf("thumbnail size = %dx%d; want 320x180", w, h)
+	}
+
+	data, err := encodeImage(thumb, FormatPNG, 80)
+	if err != nil {
+		t.Fatalf("encodeImage failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("encodeImage did not produce a PNG")
+	}
+
+	if contentName(data) != contentName(append([]byte(nil), data...)) {
+		t.Error("contentName is not deterministic")
+	}
+	if contentName(data) == contentName([]byte("other")) {
+		t.Error("contentName collided for different content")
+	}
+}
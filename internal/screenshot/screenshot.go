@@ -9,18 +9,24 @@ import (
 	_ "image/png"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/corona10/goimagehash"
+	"github.com/ismailtsdln/netvista/pkg/consent"
 	"github.com/playwright-community/playwright-go"
 )
 
 type CaptureResult struct {
-	Path      string
-	PHash     string
-	Framework string
+	Path        string
+	Thumbnail   string // Empty when thumbnails are disabled
+	PHash       string
+	DHash       string
+	AHash       string
+	DOMHash     string // SimHash of the DOM tag structure
+	TextHash    string // SimHash of the visible text
+	Frameworks  []Framework
+	ConsentRule string // Name of the consent rule that fired, if any
 }
 
 type Capturer struct {
@@ -28,13 +34,22 @@ type Capturer struct {
 	Browser        playwright.Browser
 	OutputDir      string
 	ProxyURL       string
+	ConsentRules   *consent.Rules
+	Image          ImageOptions
 	activeContexts int
 	maxContexts    int
 	mu             sync.Mutex
 }
 
-func NewCapturer(outputDir string, proxyURL string, maxContexts int) (*Capturer, error) {
-	err := playwright.Install()
+func NewCapturer(outputDir string, proxyURL string, maxContexts int, rules *consent.Rules, imgOpts ImageOptions) (*Capturer, error) {
+	imgOpts, err := imgOpts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// Install output goes to stderr so that stdout stays free for results
+	// streamed with -jsonl.
+	err = playwright.Install(&playwright.RunOptions{Verbose: true, Stdout: os.Stderr})
 	if err != nil {
 		return nil, fmt.Errorf("could not install playwright: %v", err)
 	}
@@ -54,18 +69,26 @@ func NewCapturer(outputDir string, proxyURL string, maxContexts int) (*Capturer,
 	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
 		os.MkdirAll(outputDir, 0755)
 	}
+	if imgOpts.ThumbnailWidth > 0 {
+		os.MkdirAll(filepath.Join(outputDir, "thumbs"), 0755)
+	}
 
 	return &Capturer{
 		PW:             pw,
 		Browser:        browser,
 		OutputDir:      outputDir,
 		ProxyURL:       proxyURL,
+		ConsentRules:   rules,
+		Image:          imgOpts,
 		maxContexts:    maxContexts,
 		activeContexts: 0,
 	}, nil
 }
 
-func (c *Capturer) Capture(ctx context.Context, url string, filename string) (*CaptureResult, error) {
+// Capture renders url and writes its screenshot (and thumbnail) to the output
+// directory. name is the file name without extension; it is replaced by a
+// content hash when content-addressed naming is enabled.
+func (c *Capturer) Capture(ctx context.Context, url string, name string) (*CaptureResult, error) {
 	c.mu.Lock()
 	for c.activeContexts >= c.maxContexts {
 		c.mu.Unlock()
@@ -107,70 +130,99 @@ func (c *Capturer) Capture(ctx context.Context, url string, filename string) (*C
 	}
 
 	// SPA Detection & Cookie Consent Bypass
-	framework := c.handleSmartInteractions(page)
+	frameworks, consentRule := c.handleSmartInteractions(page)
+	domHash, textHash := pageSignature(page)
 
-	path := filepath.Join(c.OutputDir, filename)
-	screenshotBytes, err := page.Screenshot(playwright.PageScreenshotOptions{
-		Path: playwright.String(path),
-	})
+	shotOpts := playwright.PageScreenshotOptions{}
+	if c.Image.Format == FormatJPEG {
+		shotOpts.Type = playwright.ScreenshotTypeJpeg
+		shotOpts.Quality = playwright.Int(c.Image.Quality)
+	}
+	screenshotBytes, err := page.Screenshot(shotOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate PHash
-	phash := ""
 	img, _, err := image.Decode(bytes.NewReader(screenshotBytes))
-	if err == nil {
-		hash, err := goimagehash.PerceptionHash(img)
-		if err == nil {
-			phash = hash.ToString()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+
+	data := screenshotBytes
+	if c.Image.Format == FormatWebP {
+		if data, err = toWebP(playContext, screenshotBytes, c.Image.Quality); err != nil {
+			return nil, err
 		}
 	}
 
-	return &CaptureResult{
-		Path:      path,
-		PHash:     phash,
-		Framework: framework,
-	}, nil
+	if c.Image.ContentAddressed {
+		name = contentName(data)
+	}
+	path := filepath.Join(c.OutputDir, name+c.Image.Ext())
+	if err := writeImage(path, data, c.Image.ContentAddressed); err != nil {
+		return nil, err
+	}
+
+	thumbPath := ""
+	if c.Image.ThumbnailWidth > 0 {
+		thumbPath = filepath.Join(c.OutputDir, "thumbs", name+c.Image.Ext())
+		if err := c.writeThumbnail(playContext, img, thumbPath); err != nil {
+			return nil, fmt.Errorf("failed to write thumbnail: %w", err)
+		}
+	}
+
+	// Generate perceptual hashes
+	res := &CaptureResult{
+		Path:        path,
+		Thumbnail:   thumbPath,
+		DOMHash:     domHash,
+		TextHash:    textHash,
+		Frameworks:  frameworks,
+		ConsentRule: consentRule,
+	}
+	if hash, err := goimagehash.PerceptionHash(img); err == nil {
+		res.PHash = hash.ToString()
+	}
+	if hash, err := goimagehash.DifferenceHash(img); err == nil {
+		res.DHash = hash.ToString()
+	}
+	if hash, err := goimagehash.AverageHash(img); err == nil {
+		res.AHash = hash.ToString()
+	}
+
+	return res, nil
 }
 
-func (c *Capturer) handleSmartInteractions(page playwright.Page) string {
-	// 1. Framework Detection
-	content, _ := page.Content()
-	framework := "Static"
-	if strings.Contains(content, "id=\"root\"") || strings.Contains(content, "_reactRootContainer") {
-		framework = "React"
-	} else if strings.Contains(content, "id=\"app\"") || strings.Contains(content, "data-v-") {
-		framework = "Vue"
-	}
-
-	// 2. Cookie Consent Auto-Click (Best-effort)
-	selectors := []string{
-		"button[id*=cookie]", "button[class*=cookie]", "button[id*=consent]",
-		"button[class*=consent]", "button[id*=accept]", "button[class*=accept]",
-		"#accept-cookies", ".accept-cookies", "#ok-cookie",
-	}
-
-	for _, sel := range selectors {
-		elements, err := page.QuerySelectorAll(sel)
-		if err == nil && len(elements) > 0 {
-			for _, el := range elements {
-				visible, _ := el.IsVisible()
-				if visible {
-					text, _ := el.TextContent()
-					text = strings.ToLower(text)
-					if strings.Contains(text, "accept") || strings.Contains(text, "ok") || strings.Contains(text, "allow") || strings.Contains(text, "agree") {
-						el.Click()
-						// Small wait for overlay to disappear
-						page.WaitForTimeout(500)
-						break
-					}
-				}
-			}
+func (c *Capturer) writeThumbnail(bctx playwright.BrowserContext, img image.Image, path string) error {
+	thumb := makeThumbnail(img, c.Image.ThumbnailWidth)
+
+	format := c.Image.Format
+	if format == FormatWebP {
+		format = FormatPNG // Encoded losslessly first, then converted by the browser
+	}
+	data, err := encodeImage(thumb, format, c.Image.Quality)
+	if err != nil {
+		return err
+	}
+	if c.Image.Format == FormatWebP {
+		if data, err = toWebP(bctx, data, c.Image.Quality); err != nil {
+			return err
 		}
 	}
+	return writeImage(path, data, c.Image.ContentAddressed)
+}
+
+func (c *Capturer) handleSmartInteractions(page playwright.Page) ([]Framework, string) {
+	// 1. Framework Detection (runtime globals and DOM markers)
+	var frameworks []Framework
+	if detected, err := page.Evaluate(frameworkDetectionScript); err == nil {
+		frameworks = parseFrameworks(detected)
+	}
+
+	// 2. Cookie Consent / Overlay Dismissal (Best-effort)
+	rule := c.dismissConsent(page)
 
-	return framework
+	return frameworks, rule
 }
 
 func (c *Capturer) Close() error {
@@ -0,0 +1,116 @@
+package screenshot
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SimHash computes a 64-bit SimHash over a list of features. Similar feature
+// sets produce hashes with a small Hamming distance. Repeated features weigh
+// proportionally more.
+func SimHash(features []string) uint64 {
+	if len(features) == 0 {
+		return 0
+	}
+
+	var counts [64]int
+	h := fnv.New64a()
+	for _, f := range features {
+		h.Reset()
+		h.Write([]byte(f))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	var out uint64
+	for bit, c := range counts {
+		if c > 0 {
+			out |= 1 << uint(bit)
+		}
+	}
+	return out
+}
+
+// FormatSimHash renders a SimHash in the same "kind:hex" style goimagehash uses.
+func FormatSimHash(kind string, h uint64) string {
+	return fmt.Sprintf("%s:%016x", kind, h)
+}
+
+// textShingles splits text into lowercase word 3-grams.
+func textShingles(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) < 3 {
+		return words
+	}
+	shingles := make([]string, 0, len(words)-2)
+	for i := 0; i+3 <= len(words); i++ {
+		shingles = append(shingles, strings.Join(words[i:i+3], " "))
+	}
+	return shingles
+}
+
+// pageStructureScript returns the DOM as a list of short tag paths (the tag
+// and up to three ancestors) plus the visible body text.
+const pageStructureScript = `() => {
+	const paths = [];
+	const walk = (el, ancestors) => {
+		if (paths.length >= 5000) return;
+		const tag = el.tagName.toLowerCase();
+		if (tag === "script" || tag === "style" || tag === "noscript") return;
+		const chain = ancestors.concat(tag).slice(-4);
+		paths.push(chain.join(">"));
+		for (const child of el.children) walk(child, chain);
+	};
+	walk(document.documentElement, []);
+	const text = document.body ? document.body.innerText || "" : "";
+	return { paths, text: text.slice(0, 200000) };
+}`
+
+// pageSignature returns the formatted DOM-structure and text SimHashes of the
+// page. A hash is left empty when the page could not be evaluated or has no
+// features for it, so that it is not mistaken for a zero hash.
+func pageSignature(page playwright.Page) (domHash, textHash string) {
+	res, err := page.Evaluate(pageStructureScript)
+	if err != nil {
+		return "", ""
+	}
+	return signatureHashes(res)
+}
+
+// signatureHashes hashes the result of pageStructureScript.
+func signatureHashes(res interface{}) (domHash, textHash string) {
+	m, ok := res.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+
+	var paths []string
+	if raw, ok := m["paths"].([]interface{}); ok {
+		for _, p := range raw {
+			if s, ok := p.(string); ok {
+				paths = append(paths, s)
+			}
+		}
+	}
+	text, _ := m["text"].(string)
+
+	if len(paths) > 0 {
+		domHash = FormatSimHash("s", SimHash(paths))
+	}
+	if shingles := textShingles(text); len(shingles) > 0 {
+		textHash = FormatSimHash("s", SimHash(shingles))
+	}
+	return domHash, textHash
+}
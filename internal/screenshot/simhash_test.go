@@ -0,0 +1,38 @@
+package screenshot
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestSimHashSimilarity(t *testing.T) {
+	a := SimHash(textShingles("Welcome to the admin console. Please sign in with your username and password to continue."))
+	b := SimHash(textShingles("Welcome to the admin console. Please sign in with your username and password to proceed."))
+	c := SimHash(textShingles("Breaking news: local team wins the championship after a dramatic overtime finish tonight."))
+
+	near := bits.OnesCount64(a ^ b)
+	far := bits.OnesCount64(a ^ c)
+	if near >= far {
+		t.Errorf("similar texts distance %d should be below unrelated texts distance %d", near, far)
+	}
+
+	if SimHash(nil) != 0 {
+		t.Error("SimHash of no features should be zero")
+	}
+	if got := FormatSimHash("t", 0xabc); got != "t:0000000000000abc" {
+		t.Errorf("FormatSimHash = %q", got)
+	}
+}
+
+func TestSignatureHashesMissing(t *testing.T) {
+	dom, text := signatureHashes(map[string]interface{}{
+		"paths": []interface{}{"html", "html>body", "html>body>img"},
+		"text":  "  \n ",
+	})
+	if dom == "" || text != "" {
+		t.Errorf("page without text: dom %q, text %q; want only a DOM hash", dom, text)
+	}
+	if dom, text := signatureHashes(nil); dom != "" || text != "" {
+		t.Errorf("failed evaluation gave hashes %q, %q; want none", dom, text)
+	}
+}
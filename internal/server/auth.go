@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Roles. Viewers may only read; admins may also triage and launch scans.
+const (
+	RoleViewer = "viewer"
+	RoleAdmin  = "admin"
+)
+
+// hashPrefix marks a password or token stored as a hex SHA-256 digest.
+const hashPrefix = "sha256:"
+
+// Auth checks HTTP basic credentials and bearer tokens.
+type Auth struct {
+	users  []credential
+	tokens []credential
+}
+
+type credential struct {
+	name   string
+	digest [sha256.Size]byte
+	role   string
+}
+
+// authFile is the YAML layout read by LoadAuth.
+type authFile struct {
+	Users []struct {
+		Name     string `yaml:"name"`
+		Password string `yaml:"password"`
+		Role     string `yaml:"role"`
+	} `yaml:"users"`
+	Tokens []struct {
+		Name  string `yaml:"name"`
+		Token string `yaml:"token"`
+		Role  string `yaml:"role"`
+	} `yaml:"tokens"`
+}
+
+// LoadAuth reads users and API tokens from a YAML file. Passwords and tokens
+// are either plain text or "sha256:" followed by the hex digest of the
+// secret. Roles default to viewer.
+func LoadAuth(path string) (*Auth, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f authFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	a := &Auth{}
+	for i, u := range f.Users {
+		c, err := newCredential(u.Name, u.Password, u.Role)
+		if err != nil {
+			return nil, fmt.Errorf("%s: user %d: %w", path, i+1, err)
+		}
+		a.users = append(a.users, c)
+	}
+	for i, t := range f.Tokens {
+		c, err := newCredential(t.Name, t.Token, t.Role)
+		if err != nil {
+			return nil, fmt.Errorf("%s: token %d: %w", path, i+1, err)
+		}
+		a.tokens = append(a.tokens, c)
+	}
+	if len(a.users) == 0 && len(a.tokens) == 0 {
+		return nil, fmt.Errorf("%s: no users or tokens", path)
+	}
+	return a, nil
+}
+
+func newCredential(name, secret, role string) (credential, error) {
+	c := credential{name: name, role: role}
+	if c.name == "" {
+		return c, fmt.Errorf("missing name")
+	}
+	switch c.role {
+	case "":
+		c.role = RoleViewer
+	case RoleViewer, RoleAdmin:
+	default:
+		return c, fmt.Errorf("unknown role %q (want %s or %s)", role, RoleViewer, RoleAdmin)
+	}
+	if hexDigest, ok := strings.CutPrefix(secret, hashPrefix); ok {
+		digest, err := hex.DecodeString(hexDigest)
+		if err != nil || len(digest) != sha256.Size {
+			return c, fmt.Errorf("invalid %s digest for %q", hashPrefix, name)
+		}
+		copy(c.digest[:], digest)
+		return c, nil
+	}
+	if secret == "" {
+		return c, fmt.Errorf("missing secret for %q", name)
+	}
+	c.digest = sha256.Sum256([]byte(secret))
+	return c, nil
+}
+
+// match returns the credential whose name and secret match, comparing in
+// constant time. An empty name matches on the secret alone.
+func match(creds []credential, name, secret string) (credential, bool) {
+	digest := sha256.Sum256([]byte(secret))
+	var found credential
+	ok := false
+	for _, c := range creds {
+		nameOK := name == "" || subtle.ConstantTimeCompare([]byte(c.name), []byte(name)) == 1
+		if subtle.ConstantTimeCompare(c.digest[:], digest[:]) == 1 && nameOK && !ok {
+			found, ok = c, true
+		}
+	}
+	return found, ok
+}
+
+// authenticate returns the identity behind the request's credentials.
+func (a *Auth) authenticate(r *http.Request) (credential, bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return match(a.tokens, "", strings.TrimSpace(token))
+	}
+	if name, password, ok := r.BasicAuth(); ok {
+		return match(a.users, name, password)
+	}
+	return credential{}, false
+}
+
+// session is who made a request, as reported by GET /api/session.
+type session struct {
+	User string `json:"user,omitempty"`
+	Role string `json:"role"`
+}
+
+type sessionKey struct{}
+
+// requestSession returns the session of an authenticated request. Without
+// authentication everyone is an admin.
+func requestSession(r *http.Request) session {
+	if s, ok := r.Context().Value(sessionKey{}).(session); ok {
+		return s
+	}
+	return session{Role: RoleAdmin}
+}
+
+// requireAuth rejects requests without valid credentials and limits viewers
+// to reading.
+func (a *Auth) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := a.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="NetVista", charset="UTF-8"`)
+			writeError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if c.role != RoleAdmin && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, http.StatusForbidden, "read-only access")
+			return
+		}
+		if info, ok := r.Context().Value(logKey{}).(*logInfo); ok {
+			info.user = c.name
+		}
+		ctx := context.WithValue(r.Context(), sessionKey{}, session{User: c.name, Role: c.role})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, requestSession(r))
+}
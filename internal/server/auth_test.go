@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAuth(t *testing.T) string {
+	t.Helper()
+	tokenDigest := sha256.Sum256([]byte("ci-secret"))
+	content := `users:
+  - name: alice
+    password: wonderland
+    role: admin
+  - name: bob
+    password: builder
+tokens:
+  - name: ci
+    token: sha256:` + hex.EncodeToString(tokenDigest[:]) + `
+    role: admin
+`
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadAuthRejectsBadEntries(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":        "users: []\n",
+		"role":         "users:\n  - {name: a, password: b, role: root}\n",
+		"no secret":    "users:\n  - {name: a}\n",
+		"short digest": "tokens:\n  - {name: a, token: 'sha256:abcd'}\n",
+	} {
+		path := filepath.Join(t.TempDir(), "auth.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		_, err := LoadAuth(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAuthRolesAndLogging(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	auth, err := LoadAuth(writeAuth(t))
+	require.NoError(t, err)
+
+	s, err := New([]string{dir}, "dashboard.html")
+	require.NoError(t, err)
+	s.SetAuth(auth)
+	var logs bytes.Buffer
+	s.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	do := func(method, path string, header http.Header) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(`{"url":"https://a.example.com","tags":["x"]}`))
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+	basic := func(user, password string) http.Header {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, password)
+		return req.Header
+	}
+	bearer := http.Header{"Authorization": {"Bearer ci-secret"}}
+	triage := "/api/reports/scan/results/0/triage"
+
+	resp := do(http.MethodGet, "/api/reports", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/reports", basic("bob", "wonderland")).StatusCode)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/reports", basic("bob", "builder")).StatusCode)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, triage, basic("bob", "builder")).StatusCode, "viewers are read-only")
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, triage, basic("alice", "wonderland")).StatusCode)
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, triage, bearer).StatusCode)
+
+	crossSite := bearer.Clone()
+	crossSite.Set("Sec-Fetch-Site", "cross-site")
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, triage, crossSite).StatusCode, "cross-origin writes are refused")
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/session", nil)
+	req.SetBasicAuth("bob", "builder")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	var sess session
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sess))
+	resp.Body.Close()
+	assert.Equal(t, session{User: "bob", Role: RoleViewer}, sess)
+
+	assert.Contains(t, logs.String(), "path=/api/reports status=401")
+	assert.Contains(t, logs.String(), "method=PUT path="+triage+" status=200")
+	assert.Contains(t, logs.String(), "user=alice")
+}
+
+func TestSessionWithoutAuth(t *testing.T) {
+	ts := newTestServer(t)
+	var sess session
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/session", &sess))
+	assert.Equal(t, RoleAdmin, sess.Role)
+}
+
+func TestSelfSignedCert(t *testing.T) {
+	cert, fingerprint, err := SelfSignedCert([]string{"localhost", "127.0.0.1"})
+	require.NoError(t, err)
+	assert.Len(t, fingerprint, 64)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	ts.StartTLS()
+	defer ts.Close()
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err, "the certificate is valid for 127.0.0.1")
+	resp.Body.Close()
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/internal/report"
+)
+
+// handleExport downloads the results matching the query, e.g. everything
+// tagged "interesting", as csv, json or txt (one URL per line).
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := s.reportData(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	q := ParseQuery(r.URL.Query())
+	matched := q.Apply(data.Results)
+
+	filename := fmt.Sprintf("netvista-%s.%s", sanitizeFilename(r.PathValue("report")), format)
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+	case "txt":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	default:
+		writeError(w, http.StatusBadRequest, "unknown format "+strconv.Quote(format)+" (want csv, json or txt)")
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	switch format {
+	case "csv":
+		writeCSV(w, matched)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(matched)
+	case "txt":
+		for _, res := range matched {
+			fmt.Fprintln(w, res.URL)
+		}
+	}
+}
+
+func writeCSV(w http.ResponseWriter, results []report.DashboardResult) {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	writer.Write([]string{"URL", "Status", "Title", "Technology", "Class", "Grade", "Cluster", "Review", "Tags", "Notes", "Findings", "Error"})
+	for _, res := range results {
+		writer.Write(report.CSVRow([]string{
+			res.URL,
+			strconv.Itoa(res.Status),
+			res.Title,
+			strings.Join(res.Tech, ", "),
+			res.PageClass,
+			res.Grade,
+			strconv.Itoa(res.Cluster),
+			res.Review,
+			strings.Join(res.Tags, ", "),
+			res.Notes,
+			report.FindingSummary(res.Findings),
+			res.Error,
+		}))
+	}
+}
+
+// sanitizeFilename keeps a report name safe for a download filename.
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ismailtsdln/netvista/internal/report"
+)
+
+// Paging limits for the results endpoint.
+const (
+	DefaultPerPage = 100
+	MaxPerPage     = 1000
+)
+
+// Query filters, sorts and pages results. It mirrors the filters of the
+// static dashboard so both behave the same.
+type Query struct {
+	Search  string // Substring of URL, title or technology
+	Title   string // Substring of the title
+	Status  string // Exact status code; "0" for no response
+	Tech    string // Exact technology name
+	Cluster string // Cluster index
+	Class   string // Page class; "none" for unclassified
+	State   string // "ok" or "error"
+	Tag     string // Triage tag
+	Review  string // Review status; "none" for untriaged
+	Sort    string // cluster, url, title, status, grade or findings
+	Desc    bool
+	Page    int // 1-based
+	PerPage int
+}
+
+// ParseQuery reads a Query from URL parameters: q, title, status, tech,
+// cluster, class, state, tag, review, sort, order (asc or desc), page and
+// per_page.
+func ParseQuery(v url.Values) Query {
+	q := Query{
+		Search:  strings.ToLower(strings.TrimSpace(v.Get("q"))),
+		Title:   strings.ToLower(strings.TrimSpace(v.Get("title"))),
+		Status:  v.Get("status"),
+		Tech:    v.Get("tech"),
+		Cluster: v.Get("cluster"),
+		Class:   v.Get("class"),
+		State:   v.Get("state"),
+		Tag:     v.Get("tag"),
+		Review:  v.Get("review"),
+		Sort:    v.Get("sort"),
+		Desc:    v.Get("order") == "desc",
+		Page:    1,
+		PerPage: DefaultPerPage,
+	}
+	if n, err := strconv.Atoi(v.Get("page")); err == nil && n > 0 {
+		q.Page = n
+	}
+	if n, err := strconv.Atoi(v.Get("per_page")); err == nil && n > 0 {
+		q.PerPage = n
+	}
+	if q.PerPage > MaxPerPage {
+		q.PerPage = MaxPerPage
+	}
+	return q
+}
+
+// Match reports whether r passes every filter of the query.
+func (q Query) Match(r *report.DashboardResult) bool {
+	if q.Search != "" && !strings.Contains(searchText(r), q.Search) {
+		return false
+	}
+	if q.Title != "" && !strings.Contains(strings.ToLower(r.Title), q.Title) {
+		return false
+	}
+	if q.Status != "" && q.Status != "all" && strconv.Itoa(r.Status) != q.Status {
+		return false
+	}
+	if q.Tech != "" && q.Tech != "all" && !contains(r.Tech, q.Tech) {
+		return false
+	}
+	if q.Cluster != "" && q.Cluster != "all" && strconv.Itoa(r.Cluster) != q.Cluster {
+		return false
+	}
+	if q.Class != "" && q.Class != "all" {
+		class := r.PageClass
+		if class == "" {
+			class = "none"
+		}
+		if class != q.Class {
+			return false
+		}
+	}
+	if q.Tag != "" && q.Tag != "all" && !contains(r.Tags, q.Tag) {
+		return false
+	}
+	if q.Review != "" && q.Review != "all" {
+		review := r.Review
+		if review == "" {
+			review = "none"
+		}
+		if review != q.Review {
+			return false
+		}
+	}
+	switch q.State {
+	case "ok":
+		return r.Error == ""
+	case "error":
+		return r.Error != ""
+	}
+	return true
+}
+
+// Apply returns the matching results in the requested order. Ties keep the
+// report order.
+func (q Query) Apply(results []report.DashboardResult) []report.DashboardResult {
+	matched := make([]report.DashboardResult, 0, len(results))
+	for i := range results {
+		if q.Match(&results[i]) {
+			matched = append(matched, results[i])
+		}
+	}
+
+	less := comparators[q.Sort]
+	if less == nil {
+		less = comparators["cluster"]
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := &matched[i], &matched[j]
+		if q.Desc {
+			a, b = b, a
+		}
+		return less(a, b)
+	})
+	return matched
+}
+
+var comparators = map[string]func(a, b *report.DashboardResult) bool{
+	"cluster":  func(a, b *report.DashboardResult) bool { return a.Cluster < b.Cluster },
+	"url":      func(a, b *report.DashboardResult) bool { return a.URL < b.URL },
+	"title":    func(a, b *report.DashboardResult) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"status":   func(a, b *report.DashboardResult) bool { return a.Status < b.Status },
+	"grade":    func(a, b *report.DashboardResult) bool { return gradeRank(a.Grade) < gradeRank(b.Grade) },
+	"findings": func(a, b *report.DashboardResult) bool { return len(a.Findings) > len(b.Findings) },
+}
+
+// gradeRank orders grades A to F, ungraded last.
+func gradeRank(grade string) int {
+	if i := strings.Index("ABCDEF", grade); grade != "" && i >= 0 {
+		return i
+	}
+	return 6
+}
+
+func searchText(r *report.DashboardResult) string {
+	return strings.ToLower(r.URL + " " + r.Title + " " + strings.Join(r.Tech, " "))
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats summarises a report.
+type Stats struct {
+	Total      int            `json:"total"`
+	Alive      int            `json:"alive"`
+	Failed     int            `json:"failed"`
+	Clusters   int            `json:"clusters"`
+	Findings   int            `json:"findings"`
+	Status     map[string]int `json:"status"`
+	Tech       map[string]int `json:"tech"`
+	Classes    map[string]int `json:"classes"`
+	Grades     map[string]int `json:"grades"`
+	Severities map[string]int `json:"severities"`
+	Tags       map[string]int `json:"tags"`
+	Reviews    map[string]int `json:"reviews"`
+}
+
+// ComputeStats counts results by status, technology, page class, grade,
+// finding severity, triage tag and review status.
+func ComputeStats(data report.DashboardData) Stats {
+	st := Stats{
+		Total:      len(data.Results),
+		Clusters:   len(data.Clusters),
+		Status:     make(map[string]int),
+		Tech:       make(map[string]int),
+		Classes:    make(map[string]int),
+		Grades:     make(map[string]int),
+		Severities: make(map[string]int),
+		Tags:       make(map[string]int),
+		Reviews:    make(map[string]int),
+	}
+	for _, r := range data.Results {
+		if r.Error != "" {
+			st.Failed++
+		} else if r.Alive {
+			st.Alive++
+		}
+		st.Status[strconv.Itoa(r.Status)]++
+		for _, t := range r.Tech {
+			st.Tech[t]++
+		}
+		if r.PageClass != "" {
+			st.Classes[r.PageClass]++
+		}
+		if r.Grade != "" {
+			st.Grades[r.Grade]++
+		}
+		for _, f := range r.Findings {
+			st.Findings++
+			st.Severities[f.Severity]++
+		}
+		for _, t := range r.Tags {
+			st.Tags[t]++
+		}
+		if r.Review != "" {
+			st.Reviews[r.Review]++
+		}
+	}
+	return st
+}
@@ -0,0 +1,518 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+)
+
+// Scan states.
+const (
+	ScanQueued    = "queued"
+	ScanRunning   = "running"
+	ScanFinished  = "finished"
+	ScanFailed    = "failed"
+	ScanCancelled = "cancelled"
+)
+
+// Limits on scans submitted through the API.
+const (
+	MaxScanTargets     = 10000
+	MaxScanConcurrency = 100
+	MaxScanTimeout     = 5 * time.Minute
+
+	maxScanBody   = 4 << 20
+	recentResults = 50 // Results replayed to clients joining a running scan
+	keepAlive     = 15 * time.Second
+)
+
+var scanNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ScanRequest is a scan submitted through the API. Zero values fall back to
+// the server's scan defaults.
+type ScanRequest struct {
+	Name        string   `json:"name,omitempty"` // Report directory name; generated when empty
+	Targets     []string `json:"targets"`
+	Concurrency int      `json:"concurrency,omitempty"`
+	Timeout     string   `json:"timeout,omitempty"` // Per-host timeout such as "10s"
+	PathProbe   bool     `json:"paths,omitempty"`
+	Baseline    string   `json:"baseline,omitempty"` // Earlier report under the scans root that notifications compare with
+}
+
+// validate trims the request and checks it against the API limits.
+func (req *ScanRequest) validate() error {
+	var targets []string
+	for _, t := range req.Targets {
+		for _, f := range strings.Fields(t) {
+			targets = append(targets, f)
+		}
+	}
+	req.Targets = targets
+	req.Name = strings.TrimSpace(req.Name)
+	req.Baseline = strings.TrimSpace(req.Baseline)
+
+	switch {
+	case len(req.Targets) == 0:
+		return errors.New("no targets")
+	case len(req.Targets) > MaxScanTargets:
+		return fmt.Errorf("more than %d targets", MaxScanTargets)
+	case req.Name != "" && !scanNamePattern.MatchString(req.Name):
+		return fmt.Errorf("invalid name %q: use letters, digits, '.', '_' and '-'", req.Name)
+	case req.Baseline != "" && !scanNamePattern.MatchString(req.Baseline):
+		return fmt.Errorf("invalid baseline %q", req.Baseline)
+	case req.Concurrency < 0 || req.Concurrency > MaxScanConcurrency:
+		return fmt.Errorf("concurrency must be at most %d", MaxScanConcurrency)
+	}
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 || d > MaxScanTimeout {
+			return fmt.Errorf("timeout must be a duration up to %s", MaxScanTimeout)
+		}
+	}
+	return nil
+}
+
+// ScanFunc runs req, writing its report to dir and passing progress to emit.
+// Dir is a new directory under the scans root; req.Baseline, when set, names
+// an existing report beside it. It must return promptly once ctx is
+// cancelled.
+type ScanFunc func(ctx context.Context, req ScanRequest, dir string, emit func(domain.Event)) error
+
+// scanManager runs scans submitted through the API.
+type scanManager struct {
+	root string
+	run  ScanFunc
+	sem  chan struct{}
+
+	mu    sync.Mutex
+	next  int
+	scans []*scan
+	byID  map[string]*scan
+}
+
+// EnableScans lets clients launch scans through the API. Each scan writes a
+// new report directory under root, which is served like any other report.
+// At most parallel scans run at once; the rest wait in the queue.
+func (s *Server) EnableScans(root string, run ScanFunc, parallel int) error {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return err
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scans = &scanManager{root: abs, run: run, sem: make(chan struct{}, parallel), byID: make(map[string]*scan)}
+	return nil
+}
+
+// scanInfo is the state of a scan as reported by the API.
+type scanInfo struct {
+	ID       string      `json:"id"`
+	Report   string      `json:"report"`
+	Status   string      `json:"status"`
+	Request  ScanRequest `json:"request"`
+	Queued   int         `json:"queued"` // Targets to scan, including discovered ones
+	Done     int         `json:"done"`
+	Failed   int         `json:"failed"`
+	Created  time.Time   `json:"created"`
+	Started  time.Time   `json:"started,omitzero"`
+	Ended    time.Time   `json:"ended,omitzero"`
+	Error    string      `json:"error,omitempty"`
+	Inflight []string    `json:"inflight,omitempty"` // URLs being scanned
+}
+
+// liveResult summarises a finished target for the live feed.
+type liveResult struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Alive    bool     `json:"alive"`
+	Error    string   `json:"error,omitempty"`
+	Tech     []string `json:"tech,omitempty"`
+	Class    string   `json:"class,omitempty"`
+	Grade    string   `json:"grade,omitempty"`
+	Findings int      `json:"findings"`
+}
+
+// scanEvent is one server-sent event: "scan" carries a scanInfo, "result"
+// a liveResult.
+type scanEvent struct {
+	name string
+	data interface{}
+}
+
+// scan is one submitted scan and the clients following it.
+type scan struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	dir    string
+
+	mu       sync.Mutex
+	info     scanInfo
+	inflight map[string]bool
+	recent   []liveResult
+	subs     map[chan scanEvent]bool
+	done     bool
+}
+
+// snapshot returns the scan state; callers hold sc.mu.
+func (sc *scan) snapshot() scanInfo {
+	info := sc.info
+	info.Inflight = make([]string, 0, len(sc.inflight))
+	for u := range sc.inflight {
+		info.Inflight = append(info.Inflight, u)
+	}
+	sort.Strings(info.Inflight)
+	return info
+}
+
+func (sc *scan) state() scanInfo {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.snapshot()
+}
+
+// broadcast sends ev to every subscriber; callers hold sc.mu. Slow clients
+// miss events rather than stalling the scan, and catch up on the final
+// state when the stream ends.
+func (sc *scan) broadcast(ev scanEvent) {
+	for ch := range sc.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// handle records a scanner event. It is called from scan workers.
+func (sc *scan) handle(e domain.Event) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	switch e.Type {
+	case domain.EventTargetsQueued:
+		sc.info.Queued += e.Total
+	case domain.EventTargetStarted:
+		sc.inflight[e.Target.URL] = true
+	case domain.EventTargetFinished:
+		delete(sc.inflight, e.Target.URL)
+		sc.info.Done++
+		if e.Result == nil {
+			break
+		}
+		if e.Result.Error != "" {
+			sc.info.Failed++
+		}
+		res := toLiveResult(e.Result)
+		sc.recent = append(sc.recent, res)
+		if len(sc.recent) > recentResults {
+			sc.recent = sc.recent[len(sc.recent)-recentResults:]
+		}
+		sc.broadcast(scanEvent{"result", res})
+	default:
+		return
+	}
+	sc.broadcast(scanEvent{"scan", sc.snapshot()})
+}
+
+func toLiveResult(r *domain.ScanResult) liveResult {
+	return liveResult{
+		URL:      r.Target.URL,
+		Title:    r.Metadata.Title,
+		Status:   r.Metadata.StatusCode,
+		Alive:    r.IsAlive,
+		Error:    r.Error,
+		Tech:     r.Metadata.Technology,
+		Class:    r.PageClass,
+		Grade:    r.SecurityGrade,
+		Findings: len(r.Findings),
+	}
+}
+
+func (sc *scan) begin() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.info.Status = ScanRunning
+	sc.info.Started = time.Now().UTC()
+	sc.broadcast(scanEvent{"scan", sc.snapshot()})
+}
+
+// finish records how the scan ended and closes every subscription.
+func (sc *scan) finish(err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	switch {
+	case sc.ctx.Err() != nil:
+		sc.info.Status = ScanCancelled
+	case err != nil:
+		sc.info.Status = ScanFailed
+		sc.info.Error = err.Error()
+	default:
+		sc.info.Status = ScanFinished
+	}
+	sc.info.Ended = time.Now().UTC()
+	sc.inflight = map[string]bool{}
+	sc.done = true
+	for ch := range sc.subs {
+		close(ch)
+	}
+	sc.subs = nil
+	sc.cancel()
+}
+
+// subscribe returns a channel of future events together with the current
+// state and recent results. The channel is nil once the scan is over.
+func (sc *scan) subscribe() (chan scanEvent, scanInfo, []liveResult) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	recent := append([]liveResult(nil), sc.recent...)
+	if sc.done {
+		return nil, sc.snapshot(), recent
+	}
+	ch := make(chan scanEvent, 256)
+	sc.subs[ch] = true
+	return ch, sc.snapshot(), recent
+}
+
+func (sc *scan) unsubscribe(ch chan scanEvent) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	delete(sc.subs, ch)
+}
+
+// mkdir creates a new report directory for a scan under root, adding a
+// numeric suffix when name is taken.
+func (m *scanManager) mkdir(name string) (string, error) {
+	if name == "" {
+		name = "scan-" + time.Now().UTC().Format("20060102-150405")
+	}
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, i)
+		}
+		dir := filepath.Join(m.root, candidate)
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !errors.Is(err, os.ErrExist) || i >= 100 {
+			return "", err
+		}
+	}
+}
+
+// start queues a scan and runs it in the background.
+func (m *scanManager) start(s *Server, req ScanRequest) (*scan, error) {
+	dir, err := m.mkdir(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sc := &scan{
+		ctx:      ctx,
+		cancel:   cancel,
+		dir:      dir,
+		inflight: make(map[string]bool),
+		subs:     make(map[chan scanEvent]bool),
+	}
+
+	m.mu.Lock()
+	m.next++
+	sc.info = scanInfo{
+		ID:      strconv.Itoa(m.next),
+		Report:  s.AddReport(filepath.Base(dir), dir),
+		Status:  ScanQueued,
+		Request: req,
+		Created: time.Now().UTC(),
+	}
+	m.scans = append(m.scans, sc)
+	m.byID[sc.info.ID] = sc
+	m.mu.Unlock()
+
+	go func() {
+		select {
+		case m.sem <- struct{}{}:
+		case <-ctx.Done():
+			sc.finish(ctx.Err())
+			return
+		}
+		defer func() { <-m.sem }()
+		sc.begin()
+		sc.finish(m.run(ctx, req, dir, sc.handle))
+	}()
+	return sc, nil
+}
+
+// scanManager returns the scan manager, writing a 404 when scans are
+// disabled.
+func (s *Server) scanManager(w http.ResponseWriter) (*scanManager, bool) {
+	s.mu.RLock()
+	m := s.scans
+	s.mu.RUnlock()
+	if m == nil {
+		writeError(w, http.StatusNotFound, "scans are disabled; start serve with -scans")
+		return nil, false
+	}
+	return m, true
+}
+
+// scan resolves the {scan} path value, writing a 404 if it is unknown.
+func (s *Server) scan(w http.ResponseWriter, r *http.Request) (*scan, bool) {
+	m, ok := s.scanManager(w)
+	if !ok {
+		return nil, false
+	}
+	m.mu.Lock()
+	sc := m.byID[r.PathValue("scan")]
+	m.mu.Unlock()
+	if sc == nil {
+		writeError(w, http.StatusNotFound, "unknown scan")
+		return nil, false
+	}
+	return sc, true
+}
+
+func (s *Server) handleScans(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.scanManager(w)
+	if !ok {
+		return
+	}
+	m.mu.Lock()
+	scans := append([]*scan(nil), m.scans...)
+	m.mu.Unlock()
+
+	infos := make([]scanInfo, 0, len(scans))
+	for i := len(scans) - 1; i >= 0; i-- {
+		infos = append(infos, scans[i].state())
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// handleStartScan queues a scan. Only JSON bodies are accepted so that
+// plain cross-site form posts cannot launch scans.
+func (s *Server) handleStartScan(w http.ResponseWriter, r *http.Request) {
+	m, ok := s.scanManager(w)
+	if !ok {
+		return
+	}
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "scan requests must be application/json")
+		return
+	}
+	var req ScanRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxScanBody)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid scan: "+err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Baseline != "" {
+		if _, err := os.Stat(filepath.Join(m.root, req.Baseline, resultsFile)); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown baseline report %q", req.Baseline))
+			return
+		}
+	}
+	sc, err := m.start(s, req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, sc.state())
+}
+
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.scan(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, sc.state())
+}
+
+// handleCancelScan stops a queued or running scan. Targets already scanned
+// are still written to its report.
+func (s *Server) handleCancelScan(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.scan(w, r)
+	if !ok {
+		return
+	}
+	sc.cancel()
+	writeJSON(w, http.StatusAccepted, sc.state())
+}
+
+// handleScanEvents streams a scan's progress as server-sent events: the
+// current state and recent results first, then every change until the scan
+// ends, finishing with its final state.
+func (s *Server) handleScanEvents(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.scan(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	ch, info, recent := sc.subscribe()
+	if ch != nil {
+		defer sc.unsubscribe(ch)
+	}
+	for _, res := range recent {
+		writeEvent(w, scanEvent{"result", res})
+	}
+	writeEvent(w, scanEvent{"scan", info})
+	flusher.Flush()
+	if ch == nil {
+		return
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case ev, open := <-ch:
+			if !open {
+				writeEvent(w, scanEvent{"scan", sc.state()})
+				flusher.Flush()
+				return
+			}
+			writeEvent(w, ev)
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, ev scanEvent) {
+	data, err := json.Marshal(ev.data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, data)
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/core/domain"
+	"github.com/ismailtsdln/netvista/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScan reports every target as scanned and writes a report for them.
+func fakeScan(t *testing.T) ScanFunc {
+	return func(ctx context.Context, req ScanRequest, dir string, emit func(domain.Event)) error {
+		if req.Baseline != "" {
+			assert.FileExists(t, filepath.Join(filepath.Dir(dir), req.Baseline, resultsFile), "baseline is a report beside dir")
+		}
+		emit(domain.Event{Type: domain.EventTargetsQueued, Total: len(req.Targets)})
+		var results []models.Target
+		for _, u := range req.Targets {
+			target := domain.Target{URL: u}
+			emit(domain.Event{Type: domain.EventTargetStarted, Target: target})
+			res := domain.ScanResult{Target: target, IsAlive: true, Metadata: domain.Metadata{Title: "Home " + u, StatusCode: 200}}
+			emit(domain.Event{Type: domain.EventTargetFinished, Target: target, Result: &res})
+			results = append(results, models.Target{URL: u, IsAlive: true})
+		}
+		writeReport(t, dir, results)
+		return nil
+	}
+}
+
+// blockingScan waits until it is cancelled.
+func blockingScan(ctx context.Context, req ScanRequest, dir string, emit func(domain.Event)) error {
+	emit(domain.Event{Type: domain.EventTargetsQueued, Total: len(req.Targets)})
+	emit(domain.Event{Type: domain.EventTargetStarted, Target: domain.Target{URL: req.Targets[0]}})
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func newScanServer(t *testing.T, run ScanFunc) (*httptest.Server, string) {
+	t.Helper()
+	s, err := New(nil, "dashboard.html")
+	require.NoError(t, err)
+	root := filepath.Join(t.TempDir(), "scans")
+	require.NoError(t, s.EnableScans(root, run, 1))
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts, root
+}
+
+// readEvents collects server-sent events until the stream ends.
+func readEvents(t *testing.T, url string) (names []string, data []string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			names = append(names, name)
+		} else if d, ok := strings.CutPrefix(line, "data: "); ok {
+			data = append(data, d)
+		}
+	}
+	return names, data
+}
+
+func TestScansDisabled(t *testing.T) {
+	ts := newTestServer(t)
+	assert.Equal(t, http.StatusNotFound, get(t, ts, "/api/scans", nil))
+	status, _ := send(t, http.MethodPost, ts.URL+"/api/scans", ScanRequest{Targets: []string{"example.com"}})
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestStartScanStreamsResults(t *testing.T) {
+	ts, root := newScanServer(t, fakeScan(t))
+
+	for _, bad := range []ScanRequest{
+		{},
+		{Targets: []string{"a.example.com"}, Name: "../escape"},
+		{Targets: []string{"a.example.com"}, Timeout: "forever"},
+		{Targets: []string{"a.example.com"}, Concurrency: MaxScanConcurrency + 1},
+		{Targets: []string{"a.example.com"}, Baseline: "../escape"},
+		{Targets: []string{"a.example.com"}, Baseline: "missing"},
+	} {
+		status, _ := send(t, http.MethodPost, ts.URL+"/api/scans", bad)
+		assert.Equal(t, http.StatusBadRequest, status, bad)
+	}
+	resp, err := http.Post(ts.URL+"/api/scans", "application/x-www-form-urlencoded", strings.NewReader("targets=a.example.com"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+
+	status, body := send(t, http.MethodPost, ts.URL+"/api/scans", ScanRequest{Name: "nightly", Targets: []string{"a.example.com\nb.example.com"}})
+	require.Equal(t, http.StatusAccepted, status, string(body))
+	var info scanInfo
+	require.NoError(t, json.Unmarshal(body, &info))
+	assert.Equal(t, "nightly", info.Report)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, info.Request.Targets)
+
+	names, data := readEvents(t, ts.URL+"/api/scans/"+info.ID+"/events")
+	require.NotEmpty(t, names)
+	assert.Equal(t, "scan", names[len(names)-1])
+	var final scanInfo
+	require.NoError(t, json.Unmarshal([]byte(data[len(data)-1]), &final))
+	assert.Equal(t, ScanFinished, final.Status)
+	assert.Equal(t, 2, final.Queued)
+	assert.Equal(t, 2, final.Done)
+
+	var results []liveResult
+	for i, name := range names {
+		if name == "result" {
+			var res liveResult
+			require.NoError(t, json.Unmarshal([]byte(data[i]), &res))
+			results = append(results, res)
+		}
+	}
+	require.Len(t, results, 2, "results are replayed to clients that join late")
+	assert.Equal(t, "Home a.example.com", results[0].Title)
+
+	// The scan's report is served like any other.
+	assert.DirExists(t, filepath.Join(root, "nightly"))
+	var page resultPage
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/reports/nightly/results", &page))
+	assert.Equal(t, 2, page.Total)
+
+	var scans []scanInfo
+	get(t, ts, "/api/scans", &scans)
+	require.Len(t, scans, 1)
+	assert.Equal(t, ScanFinished, scans[0].Status)
+
+	// A later scan can compare with the finished one.
+	status, body = send(t, http.MethodPost, ts.URL+"/api/scans", ScanRequest{Targets: []string{"a.example.com"}, Baseline: "nightly"})
+	require.Equal(t, http.StatusAccepted, status, string(body))
+	require.NoError(t, json.Unmarshal(body, &info))
+	readEvents(t, ts.URL+"/api/scans/"+info.ID+"/events")
+	assert.Equal(t, "nightly", info.Request.Baseline)
+}
+
+func TestCancelScan(t *testing.T) {
+	ts, _ := newScanServer(t, blockingScan)
+
+	_, body := send(t, http.MethodPost, ts.URL+"/api/scans", ScanRequest{Targets: []string{"a.example.com"}})
+	var running scanInfo
+	require.NoError(t, json.Unmarshal(body, &running))
+	require.Eventually(t, func() bool {
+		var info scanInfo
+		get(t, ts, "/api/scans/"+running.ID, &info)
+		return info.Status == ScanRunning && len(info.Inflight) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	_, body = send(t, http.MethodPost, ts.URL+"/api/scans", ScanRequest{Targets: []string{"b.example.com"}})
+	var queued scanInfo
+	require.NoError(t, json.Unmarshal(body, &queued))
+	assert.NotEqual(t, running.Report, queued.Report)
+
+	var info scanInfo
+	get(t, ts, "/api/scans/"+queued.ID, &info)
+	assert.Equal(t, ScanQueued, info.Status, "only one scan runs at a time")
+
+	for _, id := range []string{queued.ID, running.ID} {
+		status, _ := send(t, http.MethodDelete, ts.URL+"/api/scans/"+id, nil)
+		require.Equal(t, http.StatusAccepted, status)
+		_, data := readEvents(t, ts.URL+"/api/scans/"+id+"/events")
+		require.NoError(t, json.Unmarshal([]byte(data[len(data)-1]), &info))
+		assert.Equal(t, ScanCancelled, info.Status, id)
+	}
+	assert.Equal(t, http.StatusNotFound, get(t, ts, "/api/scans/99", nil))
+}
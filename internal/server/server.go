@@ -0,0 +1,471 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/report"
+	"github.com/ismailtsdln/netvista/pkg/models"
+)
+
+// resultsFile is the scan output the API is built from.
+const resultsFile = "results.json"
+
+// Server serves one or more report directories: the dashboard, a JSON API
+// over their results and the report files themselves.
+type Server struct {
+	templatePath string
+	newStore     func(dir string) TriageStore
+	auth         *Auth        // Nil serves everyone as admin
+	logger       *slog.Logger // Nil disables request logging
+
+	mu      sync.RWMutex
+	reports []*reportDir
+	byName  map[string]*reportDir
+	scans   *scanManager // Nil unless EnableScans was called
+}
+
+// reportDir is a report directory whose results are reloaded whenever
+// results.json changes.
+type reportDir struct {
+	name string
+	path string
+
+	triage *triageBook
+
+	mu      sync.Mutex
+	modTime time.Time
+	data    report.DashboardData
+	targets []models.Target // Parallel to data.Results
+}
+
+// New creates a server for dirs. A directory without results.json whose
+// subdirectories contain reports is expanded into those reports.
+func New(dirs []string, templatePath string) (*Server, error) {
+	s := &Server{
+		templatePath: templatePath,
+		newStore:     func(dir string) TriageStore { return NewFileTriageStore(dir) },
+		byName:       make(map[string]*reportDir),
+	}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+
+		subdirs := reportSubdirs(abs)
+		if fileExists(filepath.Join(abs, resultsFile)) || len(subdirs) == 0 {
+			s.AddReport(filepath.Base(abs), abs)
+			continue
+		}
+		for _, sub := range subdirs {
+			s.AddReport(filepath.Base(sub), sub)
+		}
+	}
+	return s, nil
+}
+
+// SetTriageStore replaces where triage is kept, which defaults to
+// triage.json in each report directory. It applies to reports added later,
+// so call it before AddReport or pass no directories to New.
+func (s *Server) SetTriageStore(newStore func(dir string) TriageStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.newStore = newStore
+}
+
+// SetAuth requires every request to carry credentials accepted by a.
+func (s *Server) SetAuth(a *Auth) {
+	s.auth = a
+}
+
+// SetLogger logs every request to logger.
+func (s *Server) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// AddReport registers a report directory and returns the name it is served
+// under, which is made unique with a numeric suffix.
+func (s *Server) AddReport(name, dir string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.reports {
+		if r.path == dir {
+			return r.name
+		}
+	}
+	unique := name
+	for i := 2; s.byName[unique] != nil; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	r := &reportDir{name: unique, path: dir, triage: &triageBook{store: s.newStore(dir)}}
+	s.reports = append(s.reports, r)
+	s.byName[unique] = r
+	return unique
+}
+
+func reportSubdirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var subdirs []string
+	for _, e := range entries {
+		sub := filepath.Join(dir, e.Name())
+		if e.IsDir() && fileExists(filepath.Join(sub, resultsFile)) {
+			subdirs = append(subdirs, sub)
+		}
+	}
+	sort.Strings(subdirs)
+	return subdirs
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Handler returns the HTTP handler for the dashboard and API. Requests that
+// change state are refused when a browser sends them cross-origin.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/session", s.handleSession)
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /assets/{file}", s.handleAsset)
+	mux.HandleFunc("GET /api/reports", s.handleReports)
+	mux.HandleFunc("GET /api/reports/{report}/results", s.handleResults)
+	mux.HandleFunc("GET /api/reports/{report}/results/{id}", s.handleResult)
+	mux.HandleFunc("GET /api/reports/{report}/clusters", s.handleClusters)
+	mux.HandleFunc("GET /api/reports/{report}/stats", s.handleStats)
+	mux.HandleFunc("GET /api/reports/{report}/screenshots/{id}", s.handleScreenshot)
+	mux.HandleFunc("PUT /api/reports/{report}/results/{id}/triage", s.handleTriage)
+	mux.HandleFunc("POST /api/reports/{report}/triage", s.handleBulkTriage)
+	mux.HandleFunc("GET /api/reports/{report}/export", s.handleExport)
+	mux.HandleFunc("GET /api/scans", s.handleScans)
+	mux.HandleFunc("POST /api/scans", s.handleStartScan)
+	mux.HandleFunc("GET /api/scans/{scan}", s.handleScan)
+	mux.HandleFunc("DELETE /api/scans/{scan}", s.handleCancelScan)
+	mux.HandleFunc("GET /api/scans/{scan}/events", s.handleScanEvents)
+	mux.HandleFunc("GET /reports/{report}/{file...}", s.handleFile)
+
+	var h http.Handler = mux
+	if s.auth != nil {
+		h = s.auth.requireAuth(h)
+	}
+	h = http.NewCrossOriginProtection().Handler(h)
+	if s.logger != nil {
+		h = logRequests(s.logger, h)
+	}
+	return h
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	data := report.ReportData{
+		Logo: "assets/logo.png",
+		Data: report.DashboardData{API: "api", Results: []report.DashboardResult{}},
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := report.WriteDashboard(w, s.templatePath, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("file")
+	content, err := report.ReadAsset(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Write(content)
+}
+
+// reportInfo describes a served report.
+type reportInfo struct {
+	Name     string    `json:"name"`
+	Total    int       `json:"total"`
+	Modified time.Time `json:"modified"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	reports := append([]*reportDir(nil), s.reports...)
+	s.mu.RUnlock()
+
+	infos := make([]reportInfo, 0, len(reports))
+	for _, rd := range reports {
+		info := reportInfo{Name: rd.name}
+		data, _, modTime, err := rd.load()
+		if err != nil {
+			info.Error = err.Error()
+		} else {
+			info.Total = data.Total
+			info.Modified = modTime
+		}
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// resultPage is one page of matching results.
+type resultPage struct {
+	Total   int                      `json:"total"` // Matching results
+	Page    int                      `json:"page"`
+	PerPage int                      `json:"per_page"`
+	Results []report.DashboardResult `json:"results"`
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := s.reportData(w, r)
+	if !ok {
+		return
+	}
+	q := ParseQuery(r.URL.Query())
+	matched := q.Apply(data.Results)
+
+	start := (q.Page - 1) * q.PerPage
+	end := start + q.PerPage
+	if start > len(matched) {
+		start = len(matched)
+	}
+	if end > len(matched) {
+		end = len(matched)
+	}
+	writeJSON(w, http.StatusOK, resultPage{
+		Total:   len(matched),
+		Page:    q.Page,
+		PerPage: q.PerPage,
+		Results: matched[start:end],
+	})
+}
+
+// resultDetail is a dashboard result with the full scan record.
+type resultDetail struct {
+	Result report.DashboardResult `json:"result"`
+	Target models.Target          `json:"target"`
+}
+
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	data, targets, ok := s.reportData(w, r)
+	if !ok {
+		return
+	}
+	id, ok := resultID(w, r, len(data.Results))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, resultDetail{Result: data.Results[id], Target: targets[id]})
+}
+
+func (s *Server) handleClusters(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := s.reportData(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, data.Clusters)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	data, _, ok := s.reportData(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, ComputeStats(data))
+}
+
+// handleScreenshot serves the thumbnail of a result, or the full screenshot
+// with ?size=full.
+func (s *Server) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	rd, ok := s.report(w, r)
+	if !ok {
+		return
+	}
+	data, targets, _, err := rd.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	id, ok := resultID(w, r, len(data.Results))
+	if !ok {
+		return
+	}
+	t := targets[id]
+	rel := t.Thumbnail
+	if rel == "" || r.URL.Query().Get("size") == "full" {
+		rel = t.Screenshot
+	}
+	if rel == "" {
+		writeError(w, http.StatusNotFound, "no screenshot")
+		return
+	}
+	rd.serveFile(w, r, rel)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	rd, ok := s.report(w, r)
+	if !ok {
+		return
+	}
+	file := r.PathValue("file")
+	if file == "" {
+		file = "report.html"
+	}
+	rd.serveFile(w, r, file)
+}
+
+// serveFile serves a file relative to the report directory, refusing paths
+// that escape it.
+func (rd *reportDir) serveFile(w http.ResponseWriter, r *http.Request, rel string) {
+	root, err := os.OpenRoot(rd.path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer root.Close()
+	name := path.Clean("/" + filepath.ToSlash(rel))[1:]
+	http.ServeFileFS(w, r, root.FS(), name)
+}
+
+// report resolves the {report} path value, writing a 404 if it is unknown.
+func (s *Server) report(w http.ResponseWriter, r *http.Request) (*reportDir, bool) {
+	s.mu.RLock()
+	rd := s.byName[r.PathValue("report")]
+	s.mu.RUnlock()
+	if rd == nil {
+		writeError(w, http.StatusNotFound, "unknown report")
+		return nil, false
+	}
+	return rd, true
+}
+
+// reportData returns the results of the {report} path value with their
+// triage, writing an error response on failure.
+func (s *Server) reportData(w http.ResponseWriter, r *http.Request) (report.DashboardData, []models.Target, bool) {
+	rd, ok := s.report(w, r)
+	if !ok {
+		return report.DashboardData{}, nil, false
+	}
+	data, targets, _, err := rd.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return report.DashboardData{}, nil, false
+	}
+	items, err := rd.triage.snapshot()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return report.DashboardData{}, nil, false
+	}
+	data.Results = withTriage(data.Results, items)
+	return data, targets, true
+}
+
+func resultID(w http.ResponseWriter, r *http.Request, n int) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 0 || id >= n {
+		writeError(w, http.StatusNotFound, "unknown result")
+		return 0, false
+	}
+	return id, true
+}
+
+// load returns the report's results, re-reading results.json when it has
+// changed. A report without results yet is empty rather than an error, so
+// directories of running scans can be listed.
+func (rd *reportDir) load() (report.DashboardData, []models.Target, time.Time, error) {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	file := filepath.Join(rd.path, resultsFile)
+	info, err := os.Stat(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return report.DashboardData{Clusters: []report.DashboardCluster{}, Results: []report.DashboardResult{}}, nil, time.Time{}, nil
+	}
+	if err != nil {
+		return report.DashboardData{}, nil, time.Time{}, err
+	}
+	if info.ModTime().Equal(rd.modTime) {
+		return rd.data, rd.targets, rd.modTime, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return report.DashboardData{}, nil, time.Time{}, err
+	}
+	var results []models.Target
+	if err := json.Unmarshal(content, &results); err != nil {
+		return report.DashboardData{}, nil, time.Time{}, fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	clusters := report.BuildClusters(results)
+	data := report.BuildDashboardData(clusters, func(t models.Target) (string, string) {
+		card := t.Thumbnail
+		if card == "" {
+			card = t.Screenshot
+		}
+		return card, t.Screenshot
+	})
+	if data.Results == nil {
+		data.Results = []report.DashboardResult{}
+	}
+	targets := make([]models.Target, 0, len(results))
+	for _, c := range clusters {
+		targets = append(targets, c.Members...)
+	}
+
+	// Images and path screenshots are fetched through the server.
+	base := "api/reports/" + url.PathEscape(rd.name)
+	for i := range data.Results {
+		res := &data.Results[i]
+		if res.Image != "" {
+			res.Image = fmt.Sprintf("%s/screenshots/%d", base, res.ID)
+		}
+		if res.Full != "" {
+			res.Full = fmt.Sprintf("%s/screenshots/%d?size=full", base, res.ID)
+		}
+		paths := make([]models.PathHit, len(res.Paths))
+		for j, p := range res.Paths {
+			if p.Screenshot != "" {
+				p.Screenshot = "reports/" + url.PathEscape(rd.name) + "/" + p.Screenshot
+			}
+			paths[j] = p
+		}
+		res.Paths = paths
+	}
+
+	rd.data, rd.targets, rd.modTime = data, targets, info.ModTime()
+	return data, targets, rd.modTime, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeReport creates a report directory with results.json and a thumbnail.
+func writeReport(t *testing.T, dir string, results []models.Target) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "screenshots", "thumbs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screenshots", "thumbs", "a.png"), []byte("thumb"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "screenshots", "a.png"), []byte("full"), 0644))
+	content, err := json.Marshal(results)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, resultsFile), content, 0644))
+}
+
+func sampleResults() []models.Target {
+	return []models.Target{
+		{URL: "https://a.example.com", IsAlive: true, GroupID: "https://a.example.com", Thumbnail: "screenshots/thumbs/a.png", Screenshot: "screenshots/a.png",
+			Metadata: models.ResponseMetadata{StatusCode: 200, Title: "Admin Login", Technology: []string{"nginx"}}, PageClass: "login", SecurityGrade: "B",
+			Findings: []models.Finding{{ID: "hsts-missing", Severity: "medium"}}},
+		{URL: "https://b.example.com", IsAlive: true, GroupID: "https://a.example.com", PHashScore: 3,
+			Metadata: models.ResponseMetadata{StatusCode: 200, Title: "Admin Login", Technology: []string{"nginx", "PHP"}}, PageClass: "login", SecurityGrade: "F"},
+		{URL: "https://c.example.com", IsAlive: true,
+			Metadata: models.ResponseMetadata{StatusCode: 404, Title: "Not Found", Technology: []string{"Apache"}}},
+		{URL: "https://d.example.com", Error: "probe failed after retries: connection refused"},
+	}
+}
+
+func get(t *testing.T, ts *httptest.Server, path string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func newTestServer(t *testing.T, dirs ...string) *httptest.Server {
+	t.Helper()
+	s, err := New(dirs, "dashboard.html")
+	require.NoError(t, err)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestResultsFilterSortAndPage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	ts := newTestServer(t, dir)
+
+	var page resultPage
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/reports/scan/results", &page))
+	assert.Equal(t, 4, page.Total)
+	assert.Equal(t, "https://a.example.com", page.Results[0].URL, "representative of the largest cluster first")
+
+	cases := map[string][]string{
+		"?tech=PHP":                       {"https://b.example.com"},
+		"?q=not+found":                    {"https://c.example.com"},
+		"?title=admin&sort=grade":         {"https://a.example.com", "https://b.example.com"},
+		"?status=200&sort=url&order=desc": {"https://b.example.com", "https://a.example.com"},
+		"?state=error":                    {"https://d.example.com"},
+		"?class=none&state=ok":            {"https://c.example.com"},
+		"?cluster=0":                      {"https://a.example.com", "https://b.example.com"},
+	}
+	for query, want := range cases {
+		var p resultPage
+		require.Equal(t, http.StatusOK, get(t, ts, "/api/reports/scan/results"+query, &p), query)
+		var got []string
+		for _, r := range p.Results {
+			got = append(got, r.URL)
+		}
+		assert.Equal(t, want, got, query)
+	}
+
+	var paged resultPage
+	get(t, ts, "/api/reports/scan/results?sort=url&per_page=3&page=2", &paged)
+	assert.Equal(t, 4, paged.Total)
+	require.Len(t, paged.Results, 1)
+	assert.Equal(t, "https://d.example.com", paged.Results[0].URL)
+}
+
+func TestDetailClustersStatsAndScreenshots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	ts := newTestServer(t, dir)
+
+	var detail resultDetail
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/reports/scan/results/0", &detail))
+	assert.Equal(t, "https://a.example.com", detail.Target.URL)
+	assert.Equal(t, "api/reports/scan/screenshots/0", detail.Result.Image)
+	assert.Equal(t, http.StatusNotFound, get(t, ts, "/api/reports/scan/results/99", nil))
+	assert.Equal(t, http.StatusNotFound, get(t, ts, "/api/reports/other/results", nil))
+
+	var clusters []map[string]interface{}
+	get(t, ts, "/api/reports/scan/clusters", &clusters)
+	require.Len(t, clusters, 3)
+	assert.EqualValues(t, 2, clusters[0]["size"])
+
+	var stats Stats
+	get(t, ts, "/api/reports/scan/stats", &stats)
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, 3, stats.Alive)
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, 2, stats.Tech["nginx"])
+	assert.Equal(t, 2, stats.Status["200"])
+	assert.Equal(t, 1, stats.Severities["medium"])
+
+	for path, want := range map[string]string{
+		"/api/reports/scan/screenshots/0":           "thumb",
+		"/api/reports/scan/screenshots/0?size=full": "full",
+		"/reports/scan/screenshots/a.png":           "full",
+	} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, want, string(body), path)
+	}
+	assert.Equal(t, http.StatusNotFound, get(t, ts, "/api/reports/scan/screenshots/3", nil))
+}
+
+func TestServeFileStaysInReport(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "scan")
+	writeReport(t, dir, sampleResults())
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0644))
+	ts := newTestServer(t, dir)
+
+	for _, path := range []string{"/reports/scan/../secret.txt", "/reports/scan/%2e%2e/secret.txt", "/reports/scan/..%2fsecret.txt"} {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NotContains(t, string(body), "secret", path)
+	}
+}
+
+func TestMultipleReports(t *testing.T) {
+	root := t.TempDir()
+	writeReport(t, filepath.Join(root, "prod"), sampleResults())
+	writeReport(t, filepath.Join(root, "staging"), sampleResults()[:1])
+	other := filepath.Join(t.TempDir(), "prod")
+	writeReport(t, other, sampleResults()[:2])
+	ts := newTestServer(t, root, other)
+
+	var reports []reportInfo
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/reports", &reports))
+	require.Len(t, reports, 3)
+	assert.Equal(t, "prod", reports[0].Name)
+	assert.Equal(t, 4, reports[0].Total)
+	assert.Equal(t, "staging", reports[1].Name)
+	assert.Equal(t, 1, reports[1].Total)
+	assert.Equal(t, "prod-2", reports[2].Name)
+	assert.Equal(t, 2, reports[2].Total)
+}
+
+func TestReloadsChangedResults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	ts := newTestServer(t, dir)
+
+	var stats Stats
+	require.Equal(t, http.StatusOK, get(t, ts, "/api/reports/scan/stats", &stats))
+	assert.Equal(t, 0, stats.Total, "a report without results is empty")
+
+	writeReport(t, dir, sampleResults())
+	get(t, ts, "/api/reports/scan/stats", &stats)
+	assert.Equal(t, 4, stats.Total)
+}
+
+func TestDashboardUsesAPI(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	ts := newTestServer(t, dir)
+
+	resp, err := http.Get(ts.URL + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `"api":"api"`)
+	assert.NotContains(t, string(body), "a.example.com", "results are fetched through the API")
+
+	resp, err = http.Get(ts.URL + "/assets/dashboard.js")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/javascript"))
+}
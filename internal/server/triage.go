@@ -0,0 +1,375 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ismailtsdln/netvista/internal/report"
+)
+
+// triageFile is where FileTriageStore keeps triage in a report directory.
+const triageFile = "triage.json"
+
+// Review statuses. An empty status means the target has not been triaged.
+const (
+	ReviewInteresting   = "interesting"
+	ReviewReviewed      = "reviewed"
+	ReviewFalsePositive = "false-positive"
+)
+
+var reviewStatuses = []string{ReviewInteresting, ReviewReviewed, ReviewFalsePositive}
+
+const (
+	maxTags     = 32
+	maxTagLen   = 64
+	maxNotesLen = 16 * 1024
+)
+
+// Triage is the review state of one target. It is keyed by URL so that it
+// survives re-running the scan into the same directory.
+type Triage struct {
+	Tags    []string  `json:"tags,omitempty"`
+	Notes   string    `json:"notes,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+// invalidTriageError is a triage rejected by validation.
+type invalidTriageError struct {
+	msg string
+}
+
+func (e *invalidTriageError) Error() string {
+	return e.msg
+}
+
+func (t Triage) empty() bool {
+	return len(t.Tags) == 0 && t.Notes == "" && t.Status == ""
+}
+
+// normalize trims and de-duplicates tags and validates the status.
+func (t *Triage) normalize() error {
+	if t.Status != "" && !contains(reviewStatuses, t.Status) {
+		return &invalidTriageError{fmt.Sprintf("unknown status %q (want one of %s)", t.Status, strings.Join(reviewStatuses, ", "))}
+	}
+	if len(t.Notes) > maxNotesLen {
+		return &invalidTriageError{fmt.Sprintf("notes exceed %d bytes", maxNotesLen)}
+	}
+	t.Tags = normalizeTags(t.Tags)
+	if len(t.Tags) > maxTags {
+		return &invalidTriageError{fmt.Sprintf("more than %d tags", maxTags)}
+	}
+	return nil
+}
+
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag == "" || len(tag) > maxTagLen || contains(out, tag) {
+			continue
+		}
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TriageStore persists the triage of one report.
+type TriageStore interface {
+	Load() (map[string]Triage, error)
+	Save(items map[string]Triage) error
+}
+
+// FileTriageStore keeps triage as JSON in the report directory.
+type FileTriageStore struct {
+	path string
+}
+
+// NewFileTriageStore creates a store writing triage.json in dir.
+func NewFileTriageStore(dir string) *FileTriageStore {
+	return &FileTriageStore{path: filepath.Join(dir, triageFile)}
+}
+
+// Load reads the stored triage; a missing file is an empty triage.
+func (s *FileTriageStore) Load() (map[string]Triage, error) {
+	items := make(map[string]Triage)
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	return items, nil
+}
+
+// Save replaces the stored triage. The file is written to a temporary name
+// and renamed so a crash never leaves it truncated.
+func (s *FileTriageStore) Save(items map[string]Triage) error {
+	content, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), triageFile+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// triageBook caches a report's triage in memory and writes every change
+// through to its store.
+type triageBook struct {
+	store TriageStore
+
+	mu     sync.Mutex
+	items  map[string]Triage
+	loaded bool
+}
+
+func (b *triageBook) load() error {
+	if b.loaded {
+		return nil
+	}
+	items, err := b.store.Load()
+	if err != nil {
+		return err
+	}
+	b.items, b.loaded = items, true
+	return nil
+}
+
+// snapshot returns a copy of all triage entries.
+func (b *triageBook) snapshot() (map[string]Triage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	items := make(map[string]Triage, len(b.items))
+	for k, v := range b.items {
+		items[k] = v
+	}
+	return items, nil
+}
+
+// update applies fn to the triage of each URL and saves the result. Entries
+// left empty are removed.
+func (b *triageBook) update(urls []string, fn func(t *Triage)) (map[string]Triage, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+
+	next := make(map[string]Triage, len(b.items)+len(urls))
+	for k, v := range b.items {
+		next[k] = v
+	}
+	now := time.Now().UTC()
+	for _, u := range urls {
+		t := next[u]
+		t.Tags = append([]string(nil), t.Tags...)
+		fn(&t)
+		if err := t.normalize(); err != nil {
+			return nil, err
+		}
+		if t.empty() {
+			delete(next, u)
+			continue
+		}
+		t.Updated = now
+		next[u] = t
+	}
+	if err := b.store.Save(next); err != nil {
+		return nil, err
+	}
+	b.items = next
+	return next, nil
+}
+
+// withTriage returns a copy of results carrying their triage.
+func withTriage(results []report.DashboardResult, items map[string]Triage) []report.DashboardResult {
+	out := make([]report.DashboardResult, len(results))
+	copy(out, results)
+	if len(items) == 0 {
+		return out
+	}
+	for i := range out {
+		if t, ok := items[out[i].URL]; ok {
+			out[i].Tags = t.Tags
+			out[i].Notes = t.Notes
+			out[i].Review = t.Status
+		}
+	}
+	return out
+}
+
+// maxTriageBody bounds triage request bodies.
+const maxTriageBody = 1 << 20
+
+// triageUpdate is the body of a single triage request. URL is the result
+// the client saw at the ID: IDs are positions in the report and change when
+// it reloads, so a request whose ID now holds another URL is refused rather
+// than applied to the wrong host.
+type triageUpdate struct {
+	URL string `json:"url"`
+	Triage
+}
+
+// handleTriage replaces the triage of one result.
+func (s *Server) handleTriage(w http.ResponseWriter, r *http.Request) {
+	rd, ok := s.report(w, r)
+	if !ok {
+		return
+	}
+	data, _, _, err := rd.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	id, ok := resultID(w, r, len(data.Results))
+	if !ok {
+		return
+	}
+	var in triageUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTriageBody)).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid triage: "+err.Error())
+		return
+	}
+	url := data.Results[id].URL
+	if in.URL == "" {
+		writeError(w, http.StatusBadRequest, "invalid triage: missing url")
+		return
+	}
+	if in.URL != url {
+		writeError(w, http.StatusConflict, fmt.Sprintf("result %d is now %s; reload the report", id, url))
+		return
+	}
+
+	items, err := rd.triage.update([]string{url}, func(t *Triage) {
+		*t = Triage{Tags: in.Tags, Notes: in.Notes, Status: in.Status}
+	})
+	if err != nil {
+		writeTriageError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, withTriage(data.Results[id:id+1], items)[0])
+}
+
+// bulkTriage changes the triage of many results at once: the listed URLs
+// and every member of the cluster with the given ID. Both are stable across
+// report reloads, unlike result and cluster positions.
+type bulkTriage struct {
+	URLs       []string `json:"urls,omitempty"`
+	Cluster    string   `json:"cluster,omitempty"`
+	AddTags    []string `json:"add_tags,omitempty"`
+	RemoveTags []string `json:"remove_tags,omitempty"`
+	Status     *string  `json:"status,omitempty"` // Empty clears the status; absent leaves it
+}
+
+func (s *Server) handleBulkTriage(w http.ResponseWriter, r *http.Request) {
+	rd, ok := s.report(w, r)
+	if !ok {
+		return
+	}
+	data, _, _, err := rd.load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var in bulkTriage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTriageBody)).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid triage: "+err.Error())
+		return
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	known := make(map[string]bool, len(data.Results))
+	for _, res := range data.Results {
+		known[res.URL] = true
+	}
+	for _, url := range in.URLs {
+		if !known[url] {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown result %s", url))
+			return
+		}
+		add(url)
+	}
+	if in.Cluster != "" {
+		cluster := -1
+		for i, c := range data.Clusters {
+			if c.ID == in.Cluster {
+				cluster = i
+			}
+		}
+		if cluster < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown cluster %s", in.Cluster))
+			return
+		}
+		for _, res := range data.Results {
+			if res.Cluster == cluster {
+				add(res.URL)
+			}
+		}
+	}
+
+	_, err = rd.triage.update(urls, func(t *Triage) {
+		t.Tags = append(t.Tags, in.AddTags...)
+		var kept []string
+		for _, tag := range t.Tags {
+			if !contains(in.RemoveTags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		t.Tags = kept
+		if in.Status != nil {
+			t.Status = *in.Status
+		}
+	})
+	if err != nil {
+		writeTriageError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"updated": len(urls)})
+}
+
+// writeTriageError reports validation errors as bad requests and storage
+// errors as server errors.
+func writeTriageError(w http.ResponseWriter, err error) {
+	var invalid *invalidTriageError
+	if errors.As(err, &invalid) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ismailtsdln/netvista/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func send(t *testing.T, method, url string, body interface{}) (int, []byte) {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	content, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, content
+}
+
+func TestTriagePersistsAndFilters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	ts := newTestServer(t, dir)
+
+	status, body := send(t, http.MethodPut, ts.URL+"/api/reports/scan/results/2/triage",
+		triageUpdate{URL: "https://c.example.com", Triage: Triage{Tags: []string{" interesting ", "404", "interesting"}, Notes: "check the <b>error</b> page", Status: ReviewReviewed}})
+	require.Equal(t, http.StatusOK, status, string(body))
+	var updated report.DashboardResult
+	require.NoError(t, json.Unmarshal(body, &updated))
+	assert.Equal(t, "https://c.example.com", updated.URL)
+	assert.Equal(t, []string{"404", "interesting"}, updated.Tags)
+
+	status, _ = send(t, http.MethodPut, ts.URL+"/api/reports/scan/results/2/triage",
+		triageUpdate{URL: "https://c.example.com", Triage: Triage{Status: "maybe"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = send(t, http.MethodPut, ts.URL+"/api/reports/scan/results/2/triage", Triage{Status: ReviewReviewed})
+	assert.Equal(t, http.StatusBadRequest, status, "the url is required")
+
+	// A client that loaded the report before it changed must not triage
+	// whichever host now sits at its ID.
+	status, body = send(t, http.MethodPut, ts.URL+"/api/reports/scan/results/2/triage",
+		triageUpdate{URL: "https://a.example.com", Triage: Triage{Status: ReviewFalsePositive}})
+	assert.Equal(t, http.StatusConflict, status, string(body))
+
+	var page resultPage
+	get(t, ts, "/api/reports/scan/results?tag=interesting", &page)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "check the <b>error</b> page", page.Results[0].Notes)
+	assert.Equal(t, ReviewReviewed, page.Results[0].Review)
+	get(t, ts, "/api/reports/scan/results?review=none", &page)
+	assert.Equal(t, 3, page.Total)
+
+	// A new server over the same directory sees the saved triage.
+	content, err := os.ReadFile(filepath.Join(dir, triageFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "https://c.example.com")
+	ts2 := newTestServer(t, dir)
+	var stats Stats
+	get(t, ts2, "/api/reports/scan/stats", &stats)
+	assert.Equal(t, 1, stats.Tags["interesting"])
+	assert.Equal(t, 1, stats.Reviews[ReviewReviewed])
+
+	// Clearing everything removes the entry.
+	status, _ = send(t, http.MethodPut, ts2.URL+"/api/reports/scan/results/2/triage", triageUpdate{URL: "https://c.example.com"})
+	require.Equal(t, http.StatusOK, status)
+	content, _ = os.ReadFile(filepath.Join(dir, triageFile))
+	assert.NotContains(t, string(content), "https://c.example.com")
+}
+
+func TestBulkTriageCluster(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	writeReport(t, dir, sampleResults())
+	ts := newTestServer(t, dir)
+
+	var clusters []report.DashboardCluster
+	get(t, ts, "/api/reports/scan/clusters", &clusters)
+	require.Equal(t, 2, clusters[0].Size)
+	fp := ReviewFalsePositive
+	status, body := send(t, http.MethodPost, ts.URL+"/api/reports/scan/triage",
+		bulkTriage{Cluster: clusters[0].ID, URLs: []string{"https://a.example.com", "https://d.example.com"}, AddTags: []string{"login-portal", "vpn"}, Status: &fp})
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.JSONEq(t, `{"updated": 3}`, string(body))
+
+	status, _ = send(t, http.MethodPost, ts.URL+"/api/reports/scan/triage", bulkTriage{URLs: []string{"https://d.example.com"}, RemoveTags: []string{"vpn"}})
+	require.Equal(t, http.StatusOK, status)
+
+	var page resultPage
+	get(t, ts, "/api/reports/scan/results?tag=vpn&sort=url", &page)
+	var urls []string
+	for _, r := range page.Results {
+		urls = append(urls, r.URL)
+		assert.Equal(t, ReviewFalsePositive, r.Review)
+	}
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, urls)
+
+	status, _ = send(t, http.MethodPost, ts.URL+"/api/reports/scan/triage", bulkTriage{Cluster: "nope", AddTags: []string{"x"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = send(t, http.MethodPost, ts.URL+"/api/reports/scan/triage", bulkTriage{URLs: []string{"https://gone.example.com"}, AddTags: []string{"x"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestExportTaggedSubset(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scan")
+	results := sampleResults()
+	results[1].Metadata.Title = `=HYPERLINK("https://evil.example","Admin Login")`
+	writeReport(t, dir, results)
+	ts := newTestServer(t, dir)
+	send(t, http.MethodPost, ts.URL+"/api/reports/scan/triage", bulkTriage{URLs: []string{"https://b.example.com", "https://c.example.com"}, AddTags: []string{"follow-up"}})
+
+	fetch := func(format string) (*http.Response, string) {
+		resp, err := http.Get(ts.URL + "/api/reports/scan/export?tag=follow-up&sort=url&format=" + format)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		content, _ := io.ReadAll(resp.Body)
+		return resp, string(content)
+	}
+
+	resp, txt := fetch("txt")
+	assert.Equal(t, "https://b.example.com\nhttps://c.example.com\n", txt)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="netvista-scan.txt"`)
+
+	_, content := fetch("csv")
+	rows, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Tags", rows[0][8])
+	assert.Equal(t, "follow-up", rows[1][8])
+	assert.Equal(t, `'=HYPERLINK("https://evil.example","Admin Login")`, rows[1][2], "formulas are escaped")
+	assert.Equal(t, "Not Found", rows[2][2])
+
+	_, content = fetch("json")
+	var exported []report.DashboardResult
+	require.NoError(t, json.Unmarshal([]byte(content), &exported))
+	assert.Len(t, exported, 2)
+	assert.Equal(t, results[1].Metadata.Title, exported[0].Title, "json keeps the raw title")
+
+	resp, _ = fetch("xml")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -7,13 +7,32 @@ import (
 )
 
 type Config struct {
-	Ports              string `yaml:"ports"`
-	Concurrency        int    `yaml:"concurrency"`
-	Output             string `yaml:"output"`
-	Timeout            string `yaml:"timeout"`
-	Proxy              string `yaml:"proxy"`
-	Headers            string `yaml:"headers"`
-	MaxBrowserContexts int    `yaml:"max_browser_contexts"`
+	Ports              string         `yaml:"ports"`
+	Concurrency        int            `yaml:"concurrency"`
+	Output             string         `yaml:"output"`
+	Timeout            string         `yaml:"timeout"`
+	Proxy              string         `yaml:"proxy"`
+	Headers            string         `yaml:"headers"`
+	MaxBrowserContexts int            `yaml:"max_browser_contexts"`
+	ConsentRules       string         `yaml:"consent_rules"`
+	ScreenshotFormat   string         `yaml:"screenshot_format"`
+	ScreenshotQuality  int            `yaml:"screenshot_quality"`
+	ThumbnailWidth     int            `yaml:"thumbnail_width"`
+	DedupeScreenshots  bool           `yaml:"dedupe_screenshots"`
+	ClusterThreshold   int            `yaml:"cluster_threshold"`
+	ClusterWeights     map[string]int `yaml:"cluster_weights"`
+	ClusterLabels      string         `yaml:"cluster_labels"`
+	SecretRules        string         `yaml:"secret_rules"`
+	MaxScripts         int            `yaml:"max_scripts"`
+	MaxScriptKB        int            `yaml:"max_script_kb"`
+	ScriptsSameSite    bool           `yaml:"scripts_same_site"`
+	ScriptDomains      []string       `yaml:"script_domains"`
+	FollowDiscovered   int            `yaml:"follow_discovered"`
+	PathProbe          bool           `yaml:"path_probe"`
+	PathWordlist       string         `yaml:"path_wordlist"`
+	DNSResolver        string         `yaml:"dns_resolver"`
+	SingleFileReport   bool           `yaml:"single_file_report"`
+	Notify             string         `yaml:"notify"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -23,6 +42,12 @@ func LoadConfig(path string) (*Config, error) {
 		Output:             "./out",
 		Timeout:            "5s",
 		MaxBrowserContexts: 10,
+		ScreenshotFormat:   "png",
+		ScreenshotQuality:  80,
+		ThumbnailWidth:     480,
+		ClusterThreshold:   11,
+		MaxScripts:         20,
+		MaxScriptKB:        2048,
 	}
 
 	if _, err := os.Stat(path); err == nil {
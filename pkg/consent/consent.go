@@ -0,0 +1,99 @@
+package consent
+
+import (
+	"embed"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yaml
+var DefaultRulesFS embed.FS
+
+// Rule describes how to dismiss one consent management platform (CMP) or overlay.
+type Rule struct {
+	Name      string   `yaml:"name"`
+	Detect    string   `yaml:"detect"`     // Selector whose presence identifies the CMP; empty always matches
+	Accept    []string `yaml:"accept"`     // Selectors of candidate accept buttons
+	MatchText bool     `yaml:"match_text"` // Require the button text to be a known accept text
+	HideCSS   string   `yaml:"hide_css"`   // CSS injected to remove the banner/overlay
+}
+
+// Rules is the full dismissal rule set, evaluated in order.
+type Rules struct {
+	Rules       []Rule   `yaml:"rules"`
+	AcceptTexts []string `yaml:"accept_texts"`
+	OverlayCSS  string   `yaml:"overlay_css"` // Injected when no rule fired, if non-empty
+}
+
+func LoadRules(path string) (*Rules, error) {
+	var data []byte
+	var err error
+
+	if path != "" {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		data, err = DefaultRulesFS.ReadFile("rules.yaml")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i, t := range rules.AcceptTexts {
+		rules.AcceptTexts[i] = normalize(t)
+	}
+
+	return &rules, nil
+}
+
+// maxAcceptWords bounds how long a button label may be and still count as an
+// accept button, so that "OK" does not match "Book a demo today".
+const maxAcceptWords = 5
+
+// IsAcceptText reports whether a button label is one of the known accept texts,
+// either exactly or as a leading/trailing phrase of a short label
+// (e.g. "OK, got it" or "Yes, I agree").
+func (r *Rules) IsAcceptText(label string) bool {
+	text := normalize(label)
+	if text == "" {
+		return false
+	}
+	words := len(strings.Fields(text))
+
+	for _, accept := range r.AcceptTexts {
+		if accept == "" {
+			continue
+		}
+		if text == accept {
+			return true
+		}
+		if words > maxAcceptWords {
+			continue
+		}
+		if strings.HasPrefix(text, accept+" ") || strings.HasSuffix(text, " "+accept) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases s, replaces punctuation with spaces and collapses whitespace.
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,35 @@
+package consent
+
+import "testing"
+
+func TestIsAcceptText(t *testing.T) {
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	tests := []struct {
+		label string
+		want  bool
+	}{
+		{"Accept all", true},
+		{"  ACCEPT ALL COOKIES ", true},
+		{"OK, got it", true},
+		{"Yes, I agree", true},
+		{"Alle akzeptieren", true},
+		{"Tout accepter", true},
+		{"J'accepte", true},
+		{"Tümünü kabul et", true},
+		{"Book a demo", false},
+		{"Look at our cookie policy", false},
+		{"Reject all", false},
+		{"OK let me tell you about our amazing product offers", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := rules.IsAcceptText(tt.label); got != tt.want {
+			t.Errorf("IsAcceptText(%q) = %v; want %v", tt.label, got, tt.want)
+		}
+	}
+}
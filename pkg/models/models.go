@@ -3,24 +3,96 @@ package models
 import "time"
 
 type Target struct {
-	Host     string
-	Scheme   string
-	Port     int
-	URL      string
-	IsAlive  bool
-	PHash    string
-	Metadata ResponseMetadata
+	Host          string
+	Scheme        string
+	Port          int
+	URL           string
+	IsAlive       bool
+	Error         string
+	PHash         string
+	Hashes        PageHashes
+	Screenshot    string // Relative to the report directory
+	Thumbnail     string // Relative to the report directory
+	GroupID       string
+	PHashScore    uint64
+	ClusterLabel  string
+	ConsentRule   string
+	PageClass     string
+	Findings      []Finding
+	SecurityScore int
+	SecurityGrade string
+	Endpoints     []Endpoint
+	Paths         []PathHit
+	CNAMEs        []string
+	Metadata      ResponseMetadata
+}
+
+type Technology struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+type PageHashes struct {
+	DHash    string `json:"dhash,omitempty"`
+	AHash    string `json:"ahash,omitempty"`
+	DOMHash  string `json:"dom_hash,omitempty"`
+	TextHash string `json:"text_hash,omitempty"`
+}
+
+type Form struct {
+	Action      string   `json:"action"`
+	Method      string   `json:"method"`
+	Inputs      []string `json:"inputs,omitempty"`
+	HasPassword bool     `json:"has_password"`
+}
+
+type Finding struct {
+	Analyzer   string `json:"analyzer"`
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Severity   string `json:"severity"`
+	Confidence string `json:"confidence,omitempty"`
+	Evidence   string `json:"evidence,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Offset     int    `json:"offset,omitempty"`
+}
+
+type Endpoint struct {
+	URL    string `json:"url"`
+	Kind   string `json:"kind"`
+	Source string `json:"source,omitempty"`
+}
+
+type PathHit struct {
+	URL        string `json:"url"`
+	Path       string `json:"path"`
+	StatusCode int    `json:"status_code"`
+	Length     int64  `json:"length"`
+	Title      string `json:"title,omitempty"`
+	Screenshot string `json:"screenshot,omitempty"` // Relative to the report directory
+	Thumbnail  string `json:"thumbnail,omitempty"`  // Relative to the report directory
 }
 
 type ResponseMetadata struct {
-	StatusCode int               `json:"status_code"`
-	Title      string            `json:"title"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
-	Technology []string          `json:"technology"`
-	ContentLen int64             `json:"content_len"`
-	Redirects  []string          `json:"redirects"`
-	Timestamp  time.Time         `json:"timestamp"`
+	StatusCode   int               `json:"status_code"`
+	Title        string            `json:"title"`
+	Headers      map[string]string `json:"headers"`
+	Cookies      []string          `json:"cookies,omitempty"`
+	Body         string            `json:"body"`
+	Technology   []string          `json:"technology"`
+	Technologies []Technology      `json:"technologies,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Generator    string            `json:"generator,omitempty"`
+	Canonical    string            `json:"canonical,omitempty"`
+	Forms        []Form            `json:"forms,omitempty"`
+	Scripts      []string          `json:"scripts,omitempty"`
+	Links        []string          `json:"links,omitempty"`
+	Iframes      []string          `json:"iframes,omitempty"`
+	ContentLen   int64             `json:"content_len"`
+	Redirects    []string          `json:"redirects"`
+	Timestamp    time.Time         `json:"timestamp"`
 }
 
 type ScanResult struct {
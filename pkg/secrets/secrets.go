@@ -0,0 +1,297 @@
+// Package secrets detects credentials, keys and other sensitive data in
+// response bodies and JavaScript files using a configurable rule set.
+package secrets
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yaml
+var DefaultRulesFS embed.FS
+
+// Scopes a rule can apply to.
+const (
+	ScopeAny    = "any"
+	ScopeBody   = "body"
+	ScopeScript = "script"
+)
+
+// maxMatchesPerRule bounds how many matches of one rule are reported per
+// document, so a minified bundle full of IPs does not flood the report.
+const maxMatchesPerRule = 5
+
+// contextRadius is the number of characters kept on each side of a match.
+const contextRadius = 40
+
+// Rule describes one kind of sensitive data.
+type Rule struct {
+	ID       string  `yaml:"id"`
+	Name     string  `yaml:"name"`
+	Pattern  string  `yaml:"pattern"`
+	Severity string  `yaml:"severity"`
+	Entropy  float64 `yaml:"entropy"` // Minimum Shannon entropy of the value; 0 disables the check
+	Scope    string  `yaml:"scope"`   // body, script or any
+	Plain    bool    `yaml:"plain"`   // Report the value unredacted
+	Block    bool    `yaml:"block"`   // The match opens a PEM block whose body is secret
+
+	re *regexp.Regexp
+}
+
+// Rules is a compiled rule set.
+type Rules struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Match is one occurrence of a rule in a document.
+type Match struct {
+	Rule    *Rule
+	Value   string // Redacted unless the rule is plain
+	Context string // Surrounding text on the same line, with every secret in it redacted
+	Offset  int    // Byte offset of the match in the document
+	Line    int    // 1-based line number
+	Column  int    // 1-based byte column
+}
+
+// LoadRules reads a rule file, falling back to the embedded defaults when
+// path is empty, and compiles every pattern.
+func LoadRules(path string) (*Rules, error) {
+	var data []byte
+	var err error
+
+	if path != "" {
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		data, err = DefaultRulesFS.ReadFile("rules.yaml")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	for i := range rules.Rules {
+		r := &rules.Rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("secret rule %d has no id", i)
+		}
+		r.re, err = regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("secret rule %s: %w", r.ID, err)
+		}
+		if r.Scope == "" {
+			r.Scope = ScopeAny
+		}
+		if r.Name == "" {
+			r.Name = r.ID
+		}
+		if r.Severity == "" {
+			r.Severity = "medium"
+		}
+	}
+
+	return &rules, nil
+}
+
+// Scan applies every rule whose scope includes scope to content.
+func (rs *Rules) Scan(content, scope string) []Match {
+	secret := rs.secretSpans(content, scope)
+	var matches []Match
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if r.re == nil || (r.Scope != ScopeAny && r.Scope != scope) {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, loc := range r.re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			value := content[start:end]
+			if seen[value] || (r.Entropy > 0 && Entropy(value) < r.Entropy) {
+				continue
+			}
+			seen[value] = true
+
+			shown := value
+			if !r.Plain {
+				shown = Redact(value)
+			}
+			line, col := position(content, start)
+			matches = append(matches, Match{
+				Rule:    r,
+				Value:   shown,
+				Context: excerpt(content, span{start: start, end: end, shown: shown}, secret),
+				Offset:  start,
+				Line:    line,
+				Column:  col,
+			})
+			if len(seen) >= maxMatchesPerRule {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// span is a part of a document shown as something else in excerpts.
+type span struct {
+	start, end int
+	shown      string
+	block      bool // Covers a PEM block body, of which nothing is shown
+}
+
+// secretSpans returns the parts of content that excerpts must hide, sorted
+// and without overlaps: the value of every non-plain match and the body of
+// every PEM block. It ignores maxMatchesPerRule, since a secret that is not
+// reported must not leak through the context of one that is.
+func (rs *Rules) secretSpans(content, scope string) []span {
+	var spans []span
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if r.re == nil || (r.Scope != ScopeAny && r.Scope != scope) || (r.Plain && !r.Block) {
+			continue
+		}
+		for _, loc := range r.re.FindAllStringSubmatchIndex(content, -1) {
+			if r.Block {
+				end := len(content)
+				if j := strings.Index(content[loc[1]:], "-----END"); j >= 0 {
+					end = loc[1] + j
+				}
+				spans = append(spans, span{start: loc[1], end: end, block: true})
+			}
+			if r.Plain {
+				continue
+			}
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if r.Entropy > 0 && Entropy(content[start:end]) < r.Entropy {
+				continue
+			}
+			spans = append(spans, span{start: start, end: end})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start < merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			merged[n-1].block = merged[n-1].block || s.block
+			continue
+		}
+		merged = append(merged, s)
+	}
+	for i := range merged {
+		merged[i].shown = "[redacted]"
+		if !merged[i].block {
+			merged[i].shown = Redact(content[merged[i].start:merged[i].end])
+		}
+	}
+	return merged
+}
+
+// Redact keeps just enough of a secret to recognise it.
+func Redact(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	stars := len(s) - 6
+	if stars > 16 {
+		stars = 16
+	}
+	return s[:4] + strings.Repeat("*", stars) + s[len(s)-2:]
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	n := float64(len(s))
+	var h float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / n
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// position converts a byte offset into a 1-based line and column.
+func position(content string, offset int) (int, int) {
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := offset - strings.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// excerpt returns the text around the match on the same line, with the
+// match replaced by its shown value and every secret span by its redacted
+// form.
+func excerpt(content string, match span, secret []span) string {
+	from := match.start - contextRadius
+	if from < 0 {
+		from = 0
+	}
+	if nl := strings.LastIndexByte(content[from:match.start], '\n'); nl >= 0 {
+		from += nl + 1
+	}
+	to := match.end + contextRadius
+	if to > len(content) {
+		to = len(content)
+	}
+	if nl := strings.IndexByte(content[match.end:to], '\n'); nl >= 0 {
+		to = match.end + nl
+	}
+
+	// The match is replaced on its own unless it is part of a secret span.
+	var spans []span
+	inSecret := false
+	for _, s := range secret {
+		if s.end > from && s.start < to {
+			spans = append(spans, s)
+			inSecret = inSecret || (s.start < match.end && match.start < s.end)
+		}
+	}
+	if !inSecret {
+		spans = append(spans, match)
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	}
+
+	var b strings.Builder
+	pos := from
+	for _, s := range spans {
+		if s.start > pos {
+			b.WriteString(strings.ToValidUTF8(content[pos:s.start], ""))
+		}
+		b.WriteString(s.shown)
+		pos = max(pos, s.end)
+	}
+	if pos < to {
+		b.WriteString(strings.ToValidUTF8(content[pos:to], ""))
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -18,8 +18,10 @@ type FingerprintSig struct {
 }
 
 type TakeoverSig struct {
-	Name        string `yaml:"name"`
-	Fingerprint string `yaml:"fingerprint"`
+	Name        string   `yaml:"name"`
+	Fingerprint string   `yaml:"fingerprint"`
+	CNAME       []string `yaml:"cname"`    // Substrings identifying the service in a CNAME chain
+	NXDomain    bool     `yaml:"nxdomain"` // Claimable when the CNAME target does not resolve
 }
 
 type WafSig struct {
@@ -1,18 +1,42 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
 	"strings"
 )
 
+var nonAlnum = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+// maxNamePrefix bounds the readable part of generated file names so that long
+// URLs stay well below filesystem name limits.
+const maxNamePrefix = 80
+
 func SanitizeFilename(s string) string {
 	// Remove scheme
 	s = strings.Replace(s, "https://", "", 1)
 	s = strings.Replace(s, "http://", "", 1)
 
 	// Replace non-alphanumeric with underscore
-	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
-	s = reg.ReplaceAllString(s, "_")
+	s = nonAlnum.ReplaceAllString(s, "_")
 
 	return strings.Trim(s, "_")
 }
+
+// ScreenshotName returns a deterministic, collision-free file name (without
+// extension) for a URL: a readable sanitized prefix followed by a short hash
+// of the full URL, so "a.com/x-y" and "a.com/x_y" map to different files.
+func ScreenshotName(url string) string {
+	prefix := SanitizeFilename(url)
+	if len(prefix) > maxNamePrefix {
+		prefix = strings.TrimRight(prefix[:maxNamePrefix], "_")
+	}
+
+	sum := sha256.Sum256([]byte(url))
+	hash := hex.EncodeToString(sum[:6])
+	if prefix == "" {
+		return hash
+	}
+	return prefix + "_" + hash
+}
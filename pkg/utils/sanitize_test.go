@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScreenshotName(t *testing.T) {
+	a := ScreenshotName("http://a.com/x-y")
+	b := ScreenshotName("http://a.com/x_y")
+	if a == b {
+		t.Fatalf("ScreenshotName collided: %q", a)
+	}
+	if !strings.HasPrefix(a, "a_com_x_y_") {
+		t.Errorf("ScreenshotName(%q) = %q; want sanitized prefix", "http://a.com/x-y", a)
+	}
+	if a != ScreenshotName("http://a.com/x-y") {
+		t.Error("ScreenshotName is not deterministic")
+	}
+	if ScreenshotName("http://a.com") == ScreenshotName("https://a.com") {
+		t.Error("ScreenshotName ignores the scheme")
+	}
+
+	long := ScreenshotName("https://example.com/" + strings.Repeat("segment/", 50))
+	if len(long) > maxNamePrefix+13 {
+		t.Errorf("ScreenshotName length = %d; want <= %d", len(long), maxNamePrefix+13)
+	}
+}
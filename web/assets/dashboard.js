@@ -0,0 +1,846 @@
+// NetVista dashboard: renders the report from the embedded JSON data and
+// handles filtering, sorting, theme toggle and the screenshot lightbox.
+// Works from file:// and without network access.
+//
+// Large reports ship their results as chunk scripts (data/results-NNNN.js)
+// that are loaded one after another in the background. Only the cards in
+// and around the viewport are kept in the DOM.
+//
+// Under `netvista serve` the embedded data only names the API, and results
+// are queried from the server a page at a time instead.
+//
+// Scanned content (titles, headers, URLs) is only ever assigned through
+// textContent or validated attributes, never parsed as HTML.
+(function () {
+    "use strict";
+
+    // Card geometry; must match .grid and .virtual-window .card in the CSS.
+    var CARD_HEIGHT = 560, CARD_MIN_WIDTH = 350, GAP = 32, BUFFER_ROWS = 2;
+    var GRADES = "ABCDEF";
+
+    var state = {
+        filters: { search: "", title: "", status: "all", tech: "all", cluster: "all", pageClass: "all", errorState: "all", tag: "all", review: "all" },
+        sortKey: "cluster",
+        sortDesc: false
+    };
+    var data = { clusters: [], results: [], chunks: [], total: 0 };
+    var rows = [];      // Loaded results, in report order
+    var view = [];      // Filtered and sorted rows
+    var layout = { cols: 1, rowHeight: CARD_HEIGHT + GAP, first: -1, last: -1 };
+    var loadedChunks = 0, failedChunk = "";
+
+    // API mode state; null for static reports.
+    var API_PAGE = 200;
+    var remote = null; // { base, report, generation, pages, total, error, admin }
+
+    function $(selector, root) { return (root || document).querySelector(selector); }
+
+    // el creates an element with an optional class and text content.
+    function el(tag, className, text) {
+        var node = document.createElement(tag);
+        if (className) node.className = className;
+        if (text !== undefined && text !== null) node.textContent = String(text);
+        return node;
+    }
+
+    // safeUrl allows http(s), relative and inline image URLs, so a hostile
+    // value cannot become a javascript: link.
+    function safeUrl(value, allowImageData) {
+        if (typeof value !== "string" || value === "") return "";
+        var v = value.trim();
+        if (/^data:/i.test(v)) return allowImageData && /^data:image\/(png|jpeg|gif|webp);base64,/i.test(v) ? v : "";
+        var scheme = /^([a-z][a-z0-9+.-]*):/i.exec(v);
+        if (scheme && !/^https?$/i.test(scheme[1])) return "";
+        return v;
+    }
+
+    function link(href, text) {
+        var a = el("a", "card-link", text);
+        var url = safeUrl(href, false);
+        if (url) {
+            a.href = url;
+            a.target = "_blank";
+            a.rel = "noopener noreferrer";
+        }
+        return a;
+    }
+
+    function metadataItem(label, value) {
+        var item = el("div", "metadata-item");
+        item.appendChild(el("span", "metadata-label", label + ":"));
+        item.appendChild(document.createTextNode(" "));
+        if (value instanceof Node) {
+            item.appendChild(value);
+        } else {
+            item.appendChild(el("span", "", value));
+        }
+        return item;
+    }
+
+    function details(summary, items, renderItem) {
+        var box = el("details", "findings");
+        box.appendChild(el("summary", "", items.length + " " + summary));
+        var list = el("ul");
+        items.forEach(function (item) { list.appendChild(renderItem(item)); });
+        box.appendChild(list);
+        return box;
+    }
+
+    function statusClass(status) {
+        if (status === 200) return "badge-success";
+        if (status >= 400) return "badge-danger";
+        return "badge-warning";
+    }
+
+    function renderFinding(f) {
+        var li = el("li", "sev-" + f.severity, "[" + f.severity + "] " + f.title);
+        var tip = "";
+        if (f.confidence) tip += "Confidence: " + f.confidence + ". ";
+        tip += f.evidence || "";
+        if (f.location) tip += " (" + f.location + (f.line ? ":" + f.line : "") + ")";
+        li.title = tip;
+        return li;
+    }
+
+    function renderPath(p) {
+        var li = el("li");
+        li.appendChild(el("code", "phash-code", p.status_code));
+        li.appendChild(document.createTextNode(" "));
+        li.appendChild(link(p.url, p.path));
+        li.appendChild(document.createTextNode(" (" + p.length + " bytes)" + (p.title ? " — " + p.title : "")));
+        if (p.screenshot) {
+            li.appendChild(document.createTextNode(" "));
+            li.appendChild(link(p.screenshot, "[screenshot]"));
+        }
+        return li;
+    }
+
+    function renderEndpoint(e) {
+        var li = el("li");
+        li.title = "Found in " + e.source;
+        li.appendChild(el("code", "phash-code", e.kind));
+        li.appendChild(document.createTextNode(" " + e.url));
+        return li;
+    }
+
+    function renderImage(r) {
+        var src = safeUrl(r.image, true);
+        if (!src) return el("div", "no-screenshot", "No Screenshot");
+        var img = el("img", "screenshot");
+        img.loading = "lazy";
+        img.alt = "Screenshot of " + r.url;
+        img.dataset.full = safeUrl(r.full, true) || src;
+        img.addEventListener("error", function () { img.replaceWith(el("div", "no-screenshot", "No Screenshot")); });
+        img.src = src;
+        return img;
+    }
+
+    function renderCard(r) {
+        var card = el("div", "card");
+        card.appendChild(renderImage(r));
+
+        var content = el("div", "card-content");
+        var title = el("div", "card-title");
+        title.appendChild(link(r.url, r.url));
+        content.appendChild(title);
+
+        var badges = el("div", "status-badge-container");
+        if (r.error) {
+            badges.appendChild(el("span", "badge badge-error", "Failed"));
+        } else {
+            badges.appendChild(el("span", "badge " + statusClass(r.status), "HTTP " + r.status));
+        }
+        if (r.grade) {
+            var grade = el("span", "badge badge-grade-" + r.grade.replace(/[^A-F]/g, ""), "Grade " + r.grade);
+            grade.title = "Header and cookie posture score " + r.score + "/100";
+            badges.appendChild(grade);
+        }
+        if (r.class) {
+            var cls = el("span", "badge badge-class", r.class);
+            cls.title = "Show only this page class";
+            cls.dataset.class = r.class;
+            badges.appendChild(cls);
+        }
+        if (r.rep) badges.appendChild(el("span", "badge badge-rep", "Representative"));
+        if (r.review) badges.appendChild(el("span", "badge badge-review-" + r.review.replace(/[^a-z-]/g, ""), r.review));
+        (r.tags || []).forEach(function (tag) {
+            var chip = el("span", "badge badge-tag", "#" + tag);
+            chip.title = "Show only this tag";
+            chip.dataset.tag = tag;
+            badges.appendChild(chip);
+        });
+        var c = data.clusters[r.cluster];
+        if (c && c.size > 1) {
+            var cluster = el("span", "badge badge-cluster", "Cluster: " + clusterLabel(r.cluster));
+            cluster.title = "Show only this cluster";
+            cluster.dataset.cluster = String(r.cluster);
+            badges.appendChild(cluster);
+        }
+        content.appendChild(badges);
+
+        var tech = el("div");
+        (r.tech || []).forEach(function (t) { tech.appendChild(el("span", "tech-tag", t)); });
+        content.appendChild(tech);
+
+        var meta = el("div", "metadata");
+        if (r.error) meta.appendChild(metadataItem("Error", el("span", "card-error", r.error)));
+        meta.appendChild(metadataItem("Title", r.title));
+        if (r.cnames && r.cnames.length) meta.appendChild(metadataItem("CNAME", r.cnames.join(" → ")));
+        if (r.phash) meta.appendChild(metadataItem("PHash", el("code", "phash-code", r.phash)));
+        if (r.findings && r.findings.length) meta.appendChild(details("findings", r.findings, renderFinding));
+        if (r.paths && r.paths.length) meta.appendChild(details("paths", r.paths, renderPath));
+        if (r.endpoints && r.endpoints.length) meta.appendChild(details("endpoints", r.endpoints, renderEndpoint));
+        if (r.notes) meta.appendChild(metadataItem("Notes", r.notes));
+        meta.appendChild(metadataItem("Time", formatTime(r.time)));
+        if (remote && remote.admin) meta.appendChild(renderTriageForm(r));
+        content.appendChild(meta);
+
+        card.appendChild(content);
+        return card;
+    }
+
+    function formatTime(value) {
+        var d = new Date(value);
+        if (isNaN(d.getTime()) || d.getFullYear() <= 1) return "";
+        function pad(n) { return n < 10 ? "0" + n : String(n); }
+        return d.getFullYear() + "-" + pad(d.getMonth() + 1) + "-" + pad(d.getDate()) + " " +
+            pad(d.getHours()) + ":" + pad(d.getMinutes()) + ":" + pad(d.getSeconds());
+    }
+
+    function clusterLabel(index) {
+        var c = data.clusters[index];
+        if (!c) return "";
+        return c.size > 1 ? c.label + " (" + c.size + ")" : c.label;
+    }
+
+    // addRows indexes newly loaded results and refreshes the view.
+    function addRows(results) {
+        (results || []).forEach(function (r) {
+            r._pos = rows.length;
+            r._search = [r.url, r.title].concat(r.tech || []).join(" ").toLowerCase();
+            r._title = (r.title || "").toLowerCase();
+            rows.push(r);
+        });
+        var statuses = {}, techs = {};
+        rows.forEach(function (r) {
+            statuses[r.status] = (statuses[r.status] || 0) + 1;
+            (r.tech || []).forEach(function (t) { techs[t] = (techs[t] || 0) + 1; });
+        });
+        updateFacets(statuses, techs);
+        refresh();
+    }
+
+    function fillSelect(select, options) {
+        var current = select.value;
+        while (select.options.length > 1) select.remove(1);
+        options.forEach(function (o) {
+            var option = el("option", "", o.label);
+            option.value = o.value;
+            select.appendChild(option);
+        });
+        select.value = current;
+        if (select.value !== current) select.value = "all";
+    }
+
+    // updateFacets fills the status and technology filters from counts,
+    // technologies most common first.
+    function updateFacets(statuses, techs) {
+        fillSelect($("#status-filter"), Object.keys(statuses).sort(function (a, b) { return a - b; }).map(function (s) {
+            return { value: s, label: (s === "0" ? "No Response" : "HTTP " + s) + " (" + statuses[s] + ")" };
+        }));
+        fillSelect($("#tech-filter"), Object.keys(techs).sort(function (a, b) { return techs[b] - techs[a] || (a < b ? -1 : 1); }).map(function (t) {
+            return { value: t, label: t + " (" + techs[t] + ")" };
+        }));
+    }
+
+    function matches(r) {
+        var f = state.filters;
+        if (f.search && r._search.indexOf(f.search) === -1) return false;
+        if (f.title && r._title.indexOf(f.title) === -1) return false;
+        if (f.status !== "all" && String(r.status) !== f.status) return false;
+        if (f.tech !== "all" && (r.tech || []).indexOf(f.tech) === -1) return false;
+        if (f.cluster !== "all" && String(r.cluster) !== f.cluster) return false;
+        if (f.pageClass !== "all" && (r.class || "none") !== f.pageClass) return false;
+        if (f.tag !== "all" && (r.tags || []).indexOf(f.tag) === -1) return false;
+        if (f.review !== "all" && (r.review || "none") !== f.review) return false;
+        if (f.errorState === "ok" && r.error) return false;
+        if (f.errorState === "error" && !r.error) return false;
+        return true;
+    }
+
+    function gradeRank(r) {
+        var i = r.grade ? GRADES.indexOf(r.grade) : -1;
+        return i === -1 ? GRADES.length : i;
+    }
+
+    function compareText(a, b) { return a < b ? -1 : a > b ? 1 : 0; }
+
+    var comparators = {
+        cluster: function (a, b) { return a.cluster - b.cluster; },
+        url: function (a, b) { return compareText(a.url, b.url); },
+        title: function (a, b) { return compareText(a._title, b._title); },
+        status: function (a, b) { return a.status - b.status; },
+        grade: function (a, b) { return gradeRank(a) - gradeRank(b); },
+        findings: function (a, b) { return (b.findings || []).length - (a.findings || []).length; }
+    };
+
+    // refresh recomputes the filtered, sorted view and redraws.
+    function refresh() {
+        updateFilterInfo();
+        if (remote) {
+            queryRemote();
+            return;
+        }
+        var cmp = comparators[state.sortKey] || comparators.cluster;
+        var dir = state.sortDesc ? -1 : 1;
+        view = rows.filter(matches);
+        view.sort(function (a, b) { return dir * cmp(a, b) || a._pos - b._pos; });
+        updateStatus();
+        resize();
+    }
+
+    function updateFilterInfo() {
+        var f = state.filters;
+        var filtering = Object.keys(f).some(function (k) { return f[k] !== "" && f[k] !== "all"; });
+        $("#filter-info").classList.toggle("hidden", !filtering);
+        $("#filter-term").textContent = f.search || f.title;
+    }
+
+    function updateStatus() {
+        if (remote) {
+            $("#load-status").textContent = remote.error ? "Could not query the server: " + remote.error :
+                "Showing " + view.length + " of " + data.total + " hosts";
+            return;
+        }
+        var text = "Showing " + view.length + " of " + rows.length + " hosts";
+        if (failedChunk) {
+            text += " — could not load " + failedChunk;
+        } else if (rows.length < data.total) {
+            text += " (loading " + loadedChunks + "/" + data.chunks.length + " pages)";
+        }
+        $("#load-status").textContent = text;
+    }
+
+    // resize recomputes the grid geometry from the container width.
+    function resize() {
+        var list = $("#results");
+        var width = list.clientWidth || CARD_MIN_WIDTH;
+        layout.cols = Math.max(1, Math.floor((width + GAP) / (CARD_MIN_WIDTH + GAP)));
+        var totalRows = Math.ceil(view.length / layout.cols);
+        list.style.height = Math.max(0, totalRows * layout.rowHeight - GAP) + "px";
+        $("#results-window").style.gridTemplateColumns = "repeat(" + layout.cols + ", minmax(0, 1fr))";
+        layout.first = layout.last = -1;
+        renderWindow();
+    }
+
+    // renderWindow draws the rows intersecting the viewport plus a buffer.
+    function renderWindow() {
+        var list = $("#results");
+        var top = Math.max(0, -list.getBoundingClientRect().top);
+        var totalRows = Math.ceil(view.length / layout.cols);
+        var first = Math.max(0, Math.floor(top / layout.rowHeight) - BUFFER_ROWS);
+        var last = Math.min(totalRows - 1, Math.ceil((top + window.innerHeight) / layout.rowHeight) + BUFFER_ROWS);
+        if (first === layout.first && last === layout.last) return;
+        layout.first = first;
+        layout.last = last;
+
+        var win = $("#results-window");
+        var fragment = document.createDocumentFragment();
+        var end = Math.min(view.length, (last + 1) * layout.cols);
+        for (var i = first * layout.cols; i < end; i++) {
+            if (view[i]) {
+                fragment.appendChild(renderCard(view[i]));
+            } else {
+                // Not fetched yet (API mode); the page redraws on arrival.
+                fragment.appendChild(el("div", "card no-screenshot", "Loading…"));
+                fetchPage(Math.floor(i / API_PAGE) + 1);
+            }
+        }
+        win.style.transform = "translateY(" + first * layout.rowHeight + "px)";
+        win.textContent = "";
+        win.appendChild(fragment);
+    }
+
+    var framePending = false;
+    function scheduleRender() {
+        if (framePending) return;
+        framePending = true;
+        window.requestAnimationFrame(function () {
+            framePending = false;
+            renderWindow();
+        });
+    }
+
+    function apiGet(path) {
+        return fetch(remote.base + path, { headers: { Accept: "application/json" } }).then(function (resp) {
+            if (!resp.ok) throw new Error(resp.status + " " + resp.statusText);
+            return resp.json();
+        });
+    }
+
+    function reportPath(suffix) {
+        return "/reports/" + encodeURIComponent(remote.report) + suffix;
+    }
+
+    // queryString encodes the filters and sort for the API; page 0 leaves out
+    // paging, as used for exports.
+    function queryString(page) {
+        var f = state.filters;
+        var params = [
+            ["q", f.search], ["title", f.title], ["status", f.status], ["tech", f.tech],
+            ["cluster", f.cluster], ["class", f.pageClass], ["state", f.errorState],
+            ["tag", f.tag], ["review", f.review],
+            ["sort", state.sortKey], ["order", state.sortDesc ? "desc" : "asc"],
+            ["page", page || ""], ["per_page", page ? API_PAGE : ""]
+        ];
+        return "?" + params.filter(function (p) { return p[1] !== "" && p[1] !== "all"; }).map(function (p) {
+            return encodeURIComponent(p[0]) + "=" + encodeURIComponent(p[1]);
+        }).join("&");
+    }
+
+    // fetchPage loads one page of the current query into view. Responses
+    // for a superseded query are dropped.
+    function fetchPage(page) {
+        if (!remote || remote.pages[page]) return;
+        remote.pages[page] = true;
+        var generation = remote.generation;
+        apiGet(reportPath("/results") + queryString(page)).then(function (res) {
+            if (generation !== remote.generation) return;
+            var resized = view.length !== res.total;
+            view.length = res.total;
+            res.results.forEach(function (r, i) { view[(page - 1) * API_PAGE + i] = r; });
+            updateStatus();
+            if (resized) {
+                resize();
+            } else {
+                layout.first = layout.last = -1;
+                renderWindow();
+            }
+        }).catch(function (err) {
+            if (generation !== remote.generation) return;
+            remote.pages[page] = false;
+            remote.error = err.message;
+            updateStatus();
+        });
+    }
+
+    function queryRemote() {
+        if (!remote.report) return;
+        ["csv", "json", "txt"].forEach(function (format) {
+            $("#export-" + format).href = remote.base + reportPath("/export") + queryString(0) + "&format=" + format;
+        });
+        $("#bulk-apply").disabled = state.filters.cluster === "all";
+        remote.generation++;
+        remote.pages = {};
+        remote.error = "";
+        view = [];
+        resize();
+        fetchPage(1);
+    }
+
+    function updateTagFacet(tags) {
+        fillSelect($("#tag-filter"), Object.keys(tags).sort().map(function (t) {
+            return { value: t, label: "#" + t + " (" + tags[t] + ")" };
+        }));
+    }
+
+    function apiSend(method, path, body) {
+        return fetch(remote.base + path, {
+            method: method,
+            headers: { "Content-Type": "application/json", Accept: "application/json" },
+            body: JSON.stringify(body)
+        }).then(function (resp) {
+            return resp.json().then(function (res) {
+                if (!resp.ok) throw new Error(res.error || resp.statusText);
+                return res;
+            });
+        });
+    }
+
+    function splitTags(value) {
+        return value.split(",").map(function (t) { return t.trim(); }).filter(Boolean);
+    }
+
+    // afterTriage reloads the tag counts and redraws the current query.
+    function afterTriage() {
+        apiGet(reportPath("/stats")).then(function (st) { updateTagFacet(st.tags || {}); });
+        queryRemote();
+    }
+
+    // renderTriageForm edits the review status, tags and notes of a result.
+    function renderTriageForm(r) {
+        var box = el("details", "findings");
+        box.appendChild(el("summary", "", "Triage"));
+        var form = el("div", "triage-form");
+
+        var status = el("select", "filter-select");
+        [["", "Untriaged"], ["interesting", "Interesting"], ["reviewed", "Reviewed"], ["false-positive", "False Positive"]].forEach(function (o) {
+            var option = el("option", "", o[1]);
+            option.value = o[0];
+            status.appendChild(option);
+        });
+        status.value = r.review || "";
+        var tags = el("input", "search-input");
+        tags.placeholder = "Tags, comma-separated";
+        tags.value = (r.tags || []).join(", ");
+        var notes = el("textarea", "search-input");
+        notes.placeholder = "Notes";
+        notes.value = r.notes || "";
+        var save = el("button", "clear-btn", "Save");
+        var message = el("span", "metadata-label");
+
+        save.addEventListener("click", function () {
+            save.disabled = true;
+            apiSend("PUT", reportPath("/results/" + r.id + "/triage"), {
+                url: r.url, status: status.value, tags: splitTags(tags.value), notes: notes.value
+            }).then(afterTriage).catch(function (err) {
+                save.disabled = false;
+                message.textContent = err.message;
+            });
+        });
+
+        [status, tags, notes, save, message].forEach(function (node) { form.appendChild(node); });
+        box.appendChild(form);
+        return box;
+    }
+
+    function bulkApply() {
+        if (state.filters.cluster === "all") return;
+        var cluster = data.clusters[Number(state.filters.cluster)];
+        if (!cluster) return;
+        var body = { cluster: cluster.id, add_tags: splitTags($("#bulk-tags").value) };
+        var status = $("#bulk-status").value;
+        if (status !== "keep") body.status = status;
+        apiSend("POST", reportPath("/triage"), body).then(function () {
+            $("#bulk-tags").value = "";
+            afterTriage();
+        }).catch(function (err) {
+            remote.error = err.message;
+            updateStatus();
+        });
+    }
+
+    // loadReport switches the dashboard to another served report.
+    function loadReport(name) {
+        remote.report = name;
+        remote.generation++;
+        Promise.all([apiGet(reportPath("/clusters")), apiGet(reportPath("/stats"))]).then(function (res) {
+            if (remote.report !== name) return;
+            data.clusters = res[0] || [];
+            data.total = res[1].total;
+            $("#stats-count").textContent = String(data.total);
+            fillSelect($("#cluster-filter"), data.clusters.map(function (c, i) {
+                return { value: String(i), label: clusterLabel(i) };
+            }));
+            updateFacets(res[1].status || {}, res[1].tech || {});
+            updateTagFacet(res[1].tags || {});
+            clearFilters();
+        }).catch(function (err) {
+            remote.error = err.message;
+            updateStatus();
+        });
+    }
+
+    // refreshReports lists the served reports, keeping the selected one.
+    function refreshReports() {
+        var select = $("#report-select");
+        return apiGet("/reports").then(function (reports) {
+            var current = select.value;
+            while (select.firstChild) select.removeChild(select.firstChild);
+            reports.forEach(function (r) {
+                var option = el("option", "", r.name + " (" + r.total + " hosts)");
+                option.value = r.name;
+                select.appendChild(option);
+            });
+            select.classList.toggle("hidden", reports.length === 0);
+            var wanted = current || decodeURIComponent(window.location.hash.slice(1));
+            if (reports.some(function (r) { return r.name === wanted; })) select.value = wanted;
+            return reports;
+        });
+    }
+
+    // Scans launched from the dashboard, by ID: { info, source }.
+    var scans = {};
+    var MAX_FEED = 50;
+
+    function scanActive(info) {
+        return info.status === "queued" || info.status === "running";
+    }
+
+    function renderScans() {
+        var list = $("#scan-list");
+        while (list.firstChild) list.removeChild(list.firstChild);
+        Object.keys(scans).sort(function (a, b) { return b - a; }).forEach(function (id) {
+            var info = scans[id].info;
+            var item = el("li");
+            var name = el("a", "card-link", info.report);
+            name.href = "#" + encodeURIComponent(info.report);
+            name.addEventListener("click", function (e) {
+                e.preventDefault();
+                showReport(info.report);
+            });
+            item.appendChild(name);
+            item.appendChild(el("span", "scan-status-" + info.status, info.status));
+            var bar = el("progress");
+            bar.max = Math.max(info.queued, 1);
+            bar.value = info.done;
+            item.appendChild(bar);
+            var counts = info.done + "/" + info.queued + " done";
+            if (info.failed) counts += ", " + info.failed + " failed";
+            if (info.error) counts += " — " + info.error;
+            item.appendChild(el("span", "metadata-label", counts));
+            if (scanActive(info) && remote.admin) {
+                var cancel = el("button", "clear-btn", "Cancel");
+                cancel.addEventListener("click", function () {
+                    cancel.disabled = true;
+                    apiSend("DELETE", "/scans/" + encodeURIComponent(id)).catch(function (err) {
+                        $("#scan-message").textContent = err.message;
+                    });
+                });
+                item.appendChild(cancel);
+            }
+            list.appendChild(item);
+        });
+    }
+
+    function addFeed(info, r) {
+        var feed = $("#scan-feed");
+        var text = info.report + ": " + (r.error ? "failed" : String(r.status || "")) + " " + r.url;
+        if (r.title) text += " — " + r.title;
+        feed.insertBefore(el("li", r.error ? "card-error" : "", text), feed.firstChild);
+        while (feed.children.length > MAX_FEED) feed.removeChild(feed.lastChild);
+    }
+
+    function showReport(name) {
+        var select = $("#report-select");
+        refreshReports().then(function () {
+            select.value = name;
+            window.location.hash = encodeURIComponent(name);
+            loadReport(name);
+        });
+    }
+
+    // followScan streams a scan's progress and results until it ends.
+    function followScan(info) {
+        var id = info.id;
+        scans[id] = { info: info, source: null };
+        renderScans();
+        if (!scanActive(info) || !window.EventSource) return;
+
+        var source = new window.EventSource(remote.base + "/scans/" + encodeURIComponent(id) + "/events");
+        scans[id].source = source;
+        source.addEventListener("result", function (e) {
+            addFeed(scans[id].info, JSON.parse(e.data));
+        });
+        source.addEventListener("scan", function (e) {
+            var next = JSON.parse(e.data);
+            var wasActive = scanActive(scans[id].info);
+            scans[id].info = next;
+            renderScans();
+            if (!scanActive(next)) {
+                source.close();
+                if (wasActive) {
+                    refreshReports();
+                    if (remote.report === next.report) loadReport(next.report);
+                }
+            }
+        });
+    }
+
+    function startScan() {
+        var button = $("#scan-start");
+        var message = $("#scan-message");
+        var body = {
+            targets: $("#scan-targets").value.split(/\s+/).filter(Boolean),
+            name: $("#scan-name").value.trim(),
+            concurrency: Number($("#scan-concurrency").value) || 0,
+            timeout: $("#scan-timeout").value.trim(),
+            paths: $("#scan-paths").checked
+        };
+        button.disabled = true;
+        message.textContent = "";
+        apiSend("POST", "/scans", body).then(function (info) {
+            $("#scan-targets").value = "";
+            $("#scan-name").value = "";
+            message.textContent = "Queued as " + info.report;
+            followScan(info);
+            refreshReports();
+        }).catch(function (err) {
+            message.textContent = err.message;
+        }).then(function () {
+            button.disabled = false;
+        });
+    }
+
+    // startScans shows the scan panel when the server accepts scans.
+    function startScans() {
+        apiGet("/scans").then(function (list) {
+            $("#scan-panel").classList.remove("hidden");
+            $("#scan-new").classList.toggle("hidden", !remote.admin);
+            $("#scan-start").addEventListener("click", startScan);
+            list.forEach(followScan);
+        }).catch(function () {
+            // Scans are disabled on this server.
+        });
+    }
+
+    // startRemote loads the dashboard from the API. Viewers get the results
+    // without the triage and scan controls.
+    function startRemote(base) {
+        remote = { base: base.replace(/\/$/, ""), report: "", generation: 0, pages: {}, error: "", admin: false };
+        var select = $("#report-select");
+        select.addEventListener("change", function () {
+            window.location.hash = encodeURIComponent(select.value);
+            loadReport(select.value);
+        });
+        $("#bulk-apply").addEventListener("click", bulkApply);
+        apiGet("/session").then(function (session) {
+            remote.admin = session.role === "admin";
+            ["#tag-filter", "#review-filter", "#triage-bar"].forEach(function (id) { $(id).classList.remove("hidden"); });
+            ["#bulk-tags", "#bulk-status", "#bulk-apply"].forEach(function (id) { $(id).classList.toggle("hidden", !remote.admin); });
+            startScans();
+            return refreshReports();
+        }).then(function () {
+            if (select.value) loadReport(select.value);
+        }).catch(function (err) {
+            remote.error = err.message;
+            updateStatus();
+        });
+    }
+
+    function loadData() {
+        var blob = document.getElementById("report-data");
+        try {
+            return JSON.parse(blob ? blob.textContent : "{}");
+        } catch (e) {
+            return {};
+        }
+    }
+
+    // loadChunk injects the script for one page; the script calls
+    // netvistaChunk, which queues the next page once the browser is idle.
+    function loadChunk(index) {
+        if (index >= data.chunks.length) return;
+        var src = safeUrl(data.chunks[index], false);
+        var script = document.createElement("script");
+        script.src = src;
+        script.onerror = function () {
+            failedChunk = data.chunks[index];
+            updateStatus();
+        };
+        script.onload = function () { script.remove(); };
+        document.body.appendChild(script);
+    }
+
+    window.netvistaChunk = function (page, results) {
+        loadedChunks++;
+        addRows(results);
+        var next = function () { loadChunk(page + 1); };
+        if (window.requestIdleCallback) {
+            window.requestIdleCallback(next, { timeout: 500 });
+        } else {
+            window.setTimeout(next, 0);
+        }
+    };
+
+    // setFilter applies a filter. Typing is debounced in API mode so each
+    // keystroke does not query the server.
+    var filterTimer = null;
+    function setFilter(key, value, typed) {
+        state.filters[key] = value;
+        window.clearTimeout(filterTimer);
+        if (remote && typed) {
+            filterTimer = window.setTimeout(refresh, 250);
+        } else {
+            refresh();
+        }
+    }
+
+    function clearFilters() {
+        state.filters = { search: "", title: "", status: "all", tech: "all", cluster: "all", pageClass: "all", errorState: "all", tag: "all", review: "all" };
+        $("#search").value = "";
+        $("#title-filter").value = "";
+        ["#status-filter", "#tech-filter", "#cluster-filter", "#class-filter", "#error-filter", "#tag-filter", "#review-filter"].forEach(function (id) {
+            $(id).value = "all";
+        });
+        refresh();
+    }
+
+    function openLightbox(src) {
+        var box = $("#lightbox");
+        $("img", box).src = src;
+        box.classList.remove("hidden");
+    }
+
+    function closeLightbox() {
+        var box = $("#lightbox");
+        box.classList.add("hidden");
+        $("img", box).removeAttribute("src");
+    }
+
+    document.addEventListener("DOMContentLoaded", function () {
+        var loaded = loadData();
+
+        $("#search").addEventListener("input", function (e) { setFilter("search", e.target.value.toLowerCase(), true); });
+        $("#title-filter").addEventListener("input", function (e) { setFilter("title", e.target.value.toLowerCase(), true); });
+        $("#status-filter").addEventListener("change", function (e) { setFilter("status", e.target.value); });
+        $("#tech-filter").addEventListener("change", function (e) { setFilter("tech", e.target.value); });
+        $("#cluster-filter").addEventListener("change", function (e) { setFilter("cluster", e.target.value); });
+        $("#class-filter").addEventListener("change", function (e) { setFilter("pageClass", e.target.value); });
+        $("#error-filter").addEventListener("change", function (e) { setFilter("errorState", e.target.value); });
+        $("#tag-filter").addEventListener("change", function (e) { setFilter("tag", e.target.value); });
+        $("#review-filter").addEventListener("change", function (e) { setFilter("review", e.target.value); });
+        $("#sort-key").addEventListener("change", function (e) {
+            state.sortKey = e.target.value;
+            refresh();
+        });
+        $("#sort-dir").addEventListener("click", function (e) {
+            state.sortDesc = !state.sortDesc;
+            e.target.textContent = state.sortDesc ? "↓" : "↑";
+            refresh();
+        });
+        $("#clear-filters").addEventListener("click", clearFilters);
+
+        var toggle = $("#theme-toggle");
+        toggle.addEventListener("click", function () {
+            var light = document.body.classList.toggle("light-mode");
+            toggle.textContent = light ? "🌙 Dark Mode" : "☀️ Light Mode";
+        });
+
+        document.addEventListener("click", function (e) {
+            var target = e.target;
+            if (target.matches("img.screenshot")) {
+                openLightbox(target.dataset.full || target.src);
+            } else if (target.matches(".badge-class")) {
+                $("#class-filter").value = target.dataset.class;
+                setFilter("pageClass", target.dataset.class);
+            } else if (target.matches(".badge-tag")) {
+                $("#tag-filter").value = target.dataset.tag;
+                setFilter("tag", target.dataset.tag);
+            } else if (target.matches(".badge-cluster")) {
+                $("#cluster-filter").value = target.dataset.cluster;
+                setFilter("cluster", target.dataset.cluster);
+            } else if (target.closest("#lightbox")) {
+                closeLightbox();
+            }
+        });
+        document.addEventListener("keydown", function (e) {
+            if (e.key === "Escape") closeLightbox();
+        });
+        window.addEventListener("scroll", scheduleRender, { passive: true });
+        window.addEventListener("resize", resize);
+
+        if (loaded.api) {
+            startRemote(loaded.api);
+            return;
+        }
+        data.clusters = loaded.clusters || [];
+        data.chunks = loaded.chunks || [];
+        data.total = loaded.total || 0;
+        fillSelect($("#cluster-filter"), data.clusters.map(function (c, i) {
+            return { value: String(i), label: clusterLabel(i) };
+        }));
+        addRows(loaded.results);
+        loadChunk(0);
+    });
+})();